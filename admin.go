@@ -0,0 +1,264 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewAdminHandler makes an http.Handler to inspect and change log levels at run-time
+// mount it on an internal port only, e.g. http.Handle("/debug/log/", NewAdminHandler(cw))
+//
+//	GET  .../             tree of named loggers as JSON, or HTML when the client accepts text/html or ?format=html
+//	POST .../             set level of a named logger: name=<name>&level=<level>, or the same as a JSON object
+//	                      add for=<duration>, e.g. for=10m, to restore the previous level after the duration
+//	GET  .../escalations  levels set with a duration that are still active
+//	GET  .../rules        file and line rules of codeWriter as JSON
+//	POST .../rules        set a rule: file=<file>[&line=<line>], function=<function> or package=<package>
+//	                      with level=<level>, or the same as a JSON object, and level=default removes the rule
+//	DELETE .../rules      remove all rules
+//
+// PUT is accepted in place of POST, and codeWriter may be nil when not used
+// changes sent by a browser from another origin are refused, so a page on another site cannot post the form
+func NewAdminHandler(codeWriter ICodeWriter) http.Handler {
+	return adminHandler{codeWriter: codeWriter}
+}
+
+type adminHandler struct {
+	codeWriter ICodeWriter
+}
+
+// AdminNode is how the admin handler lists a named logger
+type AdminNode struct {
+	Name  string      `json:"name"`
+	Names []string    `json:"names"`
+	Level Level       `json:"level"`
+	Own   Level       `json:"own"` //level set on this name, or LevelDefault when inherited
+	Subs  []AdminNode `json:"subs,omitempty"`
+}
+
+type adminRequest struct {
+	Name     string `json:"name"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function"`
+	Package  string `json:"package"`
+	Level    string `json:"level"`
+	For      string `json:"for"`
+}
+
+func (h adminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	isRules := strings.HasSuffix(path, "/rules") || path == "rules"
+	if r.Method != http.MethodGet && !sameOrigin(r) {
+		http.Error(w, "cross-origin request refused", http.StatusForbidden)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		if isRules {
+			h.getRules(w, r)
+		} else if strings.HasSuffix(path, "/escalations") || path == "escalations" {
+			writeAdminJSON(w, Escalations())
+		} else {
+			h.getLoggers(w, r)
+		}
+	case http.MethodPost, http.MethodPut:
+		req, err := parseAdminRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if isRules {
+			err = h.setRule(req)
+		} else {
+			err = h.setLevel(req)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if wantHTML(r) {
+			http.Redirect(w, r, r.URL.Path, http.StatusSeeOther)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		if !isRules || h.codeWriter == nil {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		h.codeWriter.ClearRules()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST, PUT, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+} //adminHandler.ServeHTTP()
+
+func (h adminHandler) getLoggers(w http.ResponseWriter, r *http.Request) {
+	tree := adminTree(top)
+	if wantHTML(r) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := adminPage.Execute(w, tree); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	writeAdminJSON(w, tree)
+}
+
+func (h adminHandler) getRules(w http.ResponseWriter, r *http.Request) {
+	if h.codeWriter == nil {
+		http.Error(w, "no code writer", http.StatusNotFound)
+		return
+	}
+	writeAdminJSON(w, h.codeWriter.Rules())
+}
+
+func (h adminHandler) setLevel(req adminRequest) error {
+	level, err := ParseLevel(req.Level)
+	if err != nil {
+		return err
+	}
+	if req.For != "" {
+		d, err := time.ParseDuration(req.For)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid duration %q", req.For)
+		}
+		Named(req.Name).SetLevelFor(level, d)
+		return nil
+	}
+	if req.Name == "" {
+		SetGlobalLevel(level)
+	} else {
+		Named(req.Name).SetLevel(level)
+	}
+	return nil
+}
+
+func (h adminHandler) setRule(req adminRequest) error {
+	if h.codeWriter == nil {
+		return fmt.Errorf("no code writer")
+	}
+	level, err := ParseLevel(req.Level)
+	if err != nil {
+		return err
+	}
+	switch {
+	case req.File != "" && req.Function == "" && req.Package == "":
+		if req.Line > 0 {
+			h.codeWriter.SetFileLineLevel(req.File, req.Line, level)
+		} else {
+			h.codeWriter.SetFileLevel(req.File, level)
+		}
+	case req.Function != "" && req.File == "" && req.Package == "":
+		h.codeWriter.SetFunctionLevel(req.Function, level)
+	case req.Package != "" && req.File == "" && req.Function == "":
+		h.codeWriter.SetPackageLevel(req.Package, level)
+	default:
+		return fmt.Errorf("need one of file, function or package")
+	}
+	return nil
+}
+
+// parseAdminRequest reads a JSON body or form values
+func parseAdminRequest(r *http.Request) (adminRequest, error) {
+	req := adminRequest{}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return req, fmt.Errorf("invalid JSON body: %v", err)
+		}
+		return req, nil
+	}
+	req.Name = r.FormValue("name")
+	req.File = r.FormValue("file")
+	req.Function = r.FormValue("function")
+	req.Package = r.FormValue("package")
+	req.Level = r.FormValue("level")
+	req.For = r.FormValue("for")
+	if s := r.FormValue("line"); s != "" {
+		line, err := strconv.Atoi(s)
+		if err != nil {
+			return req, fmt.Errorf("invalid line %q", s)
+		}
+		req.Line = line
+	}
+	return req, nil
+}
+
+// sameOrigin says if a request was not sent by a browser from another origin
+// browsers send Sec-Fetch-Site, Origin or Referer with a form post, while e.g. curl sends none of them
+func sameOrigin(r *http.Request) bool {
+	if site := r.Header.Get("Sec-Fetch-Site"); site != "" {
+		return site == "same-origin" || site == "none"
+	}
+	for _, h := range []string{"Origin", "Referer"} {
+		if s := r.Header.Get(h); s != "" {
+			u, err := url.Parse(s)
+			return err == nil && u.Host == r.Host
+		}
+	}
+	return true
+}
+
+func wantHTML(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "html" || strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
+func writeAdminJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// adminTree lists the named logger and its subs sorted by name
+func adminTree(n INamed) AdminNode {
+	node := AdminNode{
+		Name:  n.Name(),
+		Names: n.Names(),
+		Level: n.Level(),
+		Own:   LevelDefault,
+	}
+	if nl, ok := n.(*named); ok {
+		treeMutex.RLock()
+		node.Own = nl.ownLevel
+		treeMutex.RUnlock()
+	}
+	for _, sub := range n.All() {
+		node.Subs = append(node.Subs, adminTree(sub))
+	}
+	sort.Slice(node.Subs, func(i, j int) bool { return node.Subs[i].Name < node.Subs[j].Name })
+	return node
+}
+
+var adminLevels = []Level{LevelDefault, LevelPanic, LevelFatal, LevelError, LevelWarn, LevelInfo, LevelDebug, LevelTrace}
+
+var adminPage = template.Must(template.New("admin").Funcs(template.FuncMap{
+	"levels": func() []Level { return adminLevels },
+}).Parse(`<!DOCTYPE html>
+<html><head><title>Loggers</title></head>
+<body>
+<h1>Loggers</h1>
+<ul>{{template "node" .}}</ul>
+{{define "node"}}
+<li>
+<form method="POST" action="?format=html">
+<input type="hidden" name="name" value="{{.Name}}">
+<code>{{if .Name}}{{.Name}}{{else}}(global){{end}}</code>: {{.Level}}
+<select name="level">{{range levels}}<option{{if eq . $.Own}} selected{{end}}>{{.}}</option>{{end}}</select>
+<input name="for" size="5" placeholder="for 10m">
+<input type="submit" value="Set">
+</form>
+{{if .Subs}}<ul>{{range .Subs}}{{template "node" .}}{{end}}</ul>{{end}}
+</li>
+{{end}}
+</body></html>
+`))
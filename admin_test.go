@@ -0,0 +1,127 @@
+package logger_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-msvc/logger"
+)
+
+func TestAdminHandler(t *testing.T) {
+	cw := logger.NewCodeWriter(&testWriter{}, logger.LevelError)
+	h := logger.NewAdminHandler(cw)
+	l := logger.Named("example.com/admin/pkg")
+
+	//set level with form and with json
+	req := httptest.NewRequest(http.MethodPost, "/debug/log/", strings.NewReader(url.Values{"name": {"example.com/admin"}, "level": {"debug"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	if res.Code != http.StatusNoContent || l.Level() != logger.LevelDebug {
+		t.Fatalf("form: %d %s level=%s", res.Code, res.Body.String(), l.Level())
+	}
+	req = httptest.NewRequest(http.MethodPut, "/debug/log", strings.NewReader(`{"name":"example.com/admin/pkg","level":"warn"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res = httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	if res.Code != http.StatusNoContent || l.Level() != logger.LevelWarn {
+		t.Fatalf("json: %d %s level=%s", res.Code, res.Body.String(), l.Level())
+	}
+	req = httptest.NewRequest(http.MethodPut, "/debug/log", strings.NewReader(`{"name":"example.com/admin/pkg","level":"loud"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res = httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	if res.Code != http.StatusBadRequest {
+		t.Fatalf("invalid level: %d", res.Code)
+	}
+
+	//list the tree
+	res = httptest.NewRecorder()
+	h.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/debug/log/", nil))
+	var tree logger.AdminNode
+	if err := json.Unmarshal(res.Body.Bytes(), &tree); err != nil {
+		t.Fatalf("invalid tree: %v: %s", err, res.Body.String())
+	}
+	node := findAdminNode(tree, "example.com/admin/pkg")
+	if node == nil || node.Level != logger.LevelWarn || strings.Join(node.Names, "/") != "example.com/admin/pkg" {
+		t.Fatalf("node not listed: %+v", node)
+	}
+	req = httptest.NewRequest(http.MethodGet, "/debug/log/", nil)
+	req.Header.Set("Accept", "text/html")
+	res = httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	if !strings.Contains(res.Body.String(), "<code>example.com/admin/pkg</code>: WARN") || !strings.Contains(res.Body.String(), "<option selected>WARN</option>") {
+		t.Fatalf("html: %s", res.Body.String())
+	}
+
+	//rules
+	req = httptest.NewRequest(http.MethodPost, "/debug/log/rules", strings.NewReader(`{"file":"example.com/admin/pkg/a.go","line":12,"level":"debug"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res = httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	if res.Code != http.StatusNoContent {
+		t.Fatalf("rule: %d %s", res.Code, res.Body.String())
+	}
+	res = httptest.NewRecorder()
+	h.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/debug/log/rules", nil))
+	var rules []logger.CodeRule
+	if err := json.Unmarshal(res.Body.Bytes(), &rules); err != nil || len(rules) != 1 || rules[0].Line != 12 || rules[0].Level != logger.LevelDebug {
+		t.Fatalf("rules: %v %s", err, res.Body.String())
+	}
+
+	res = httptest.NewRecorder()
+	h.ServeHTTP(res, httptest.NewRequest(http.MethodDelete, "/debug/log/rules", nil))
+	if res.Code != http.StatusNoContent || len(cw.Rules()) != 0 {
+		t.Fatalf("rules not cleared: %d %+v", res.Code, cw.Rules())
+	}
+
+	logger.Named("example.com/admin").SetLevel(logger.LevelDefault)
+	logger.Named("example.com/admin/pkg").SetLevel(logger.LevelDefault)
+}
+
+func TestAdminHandlerOrigin(t *testing.T) {
+	h := logger.NewAdminHandler(nil)
+	l := logger.Named("example.com/admin/origin")
+	defer l.ResetLevel()
+	tests := []struct {
+		header string
+		value  string
+		code   int
+	}{
+		{"Origin", "http://evil.example", http.StatusForbidden},
+		{"Origin", "null", http.StatusForbidden},
+		{"Referer", "http://evil.example/page", http.StatusForbidden},
+		{"Sec-Fetch-Site", "cross-site", http.StatusForbidden},
+		{"Origin", "http://example.com", http.StatusNoContent}, //host of httptest.NewRequest()
+		{"Sec-Fetch-Site", "same-origin", http.StatusNoContent},
+		{"", "", http.StatusNoContent},
+	}
+	for index, test := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/debug/log/", strings.NewReader(url.Values{"name": {l.Name()}, "level": {"debug"}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if test.header != "" {
+			req.Header.Set(test.header, test.value)
+		}
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, req)
+		if res.Code != test.code {
+			t.Fatalf("test[%d] %s: %s -> %d != %d", index, test.header, test.value, res.Code, test.code)
+		}
+	}
+}
+
+func findAdminNode(n logger.AdminNode, name string) *logger.AdminNode {
+	if n.Name == name {
+		return &n
+	}
+	for _, sub := range n.Subs {
+		if found := findAdminNode(sub, name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// IAsyncWriter writes records in a background goroutine
+type IAsyncWriter interface {
+	IWriter
+	IFlusher
+	ICloser          //Close writes the queued records, stops then closes the writer, records written after Close() are dropped
+	Dropped() uint64 //number of records dropped because the queue was full or the writer was closed
+}
+
+// AsyncConfig controls NewAsyncWriter()
+type AsyncConfig struct {
+	QueueSize int            //default 1024
+	Overflow  OverflowPolicy //what to do when the queue is full
+	DropLevel Level          //with OverflowDropBelow: drop records less severe than this level
+}
+
+// OverflowPolicy says what an async writer does when its queue is full
+type OverflowPolicy int
+
+const (
+	OverflowBlock      OverflowPolicy = iota //wait for space in the queue
+	OverflowDropNewest                       //drop the record being written
+	OverflowDropOldest                       //drop the oldest record in the queue to make space
+	OverflowDropBelow                        //drop the record if less severe than DropLevel, else wait for space
+)
+
+// NewAsyncWriter makes a writer that queues records and writes them to w in a background goroutine
+// so that a slow writer does not delay the code that is logging
+// call Close() on shutdown to write the queued records
+func NewAsyncWriter(w IWriter, c AsyncConfig) IAsyncWriter {
+	if c.QueueSize <= 0 {
+		c.QueueSize = 1024
+	}
+	aw := &asyncWriter{
+		writer:  w,
+		config:  c,
+		queue:   make(chan Record, c.QueueSize),
+		closed:  make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go aw.run()
+	return aw
+}
+
+type asyncWriter struct {
+	writer   IWriter
+	config   AsyncConfig
+	queue    chan Record
+	closing  sync.RWMutex
+	isClosed bool
+	closed   chan struct{} //closed by Close()
+	stopped  chan struct{} //closed when run() ended
+
+	queued  uint64 //records put in the queue, accessed atomically
+	dropped uint64 //accessed atomically
+
+	mutex  sync.Mutex
+	done   uint64        //records taken from the queue, written or dropped
+	notify chan struct{} //closed when done changes and Flush() is waiting
+}
+
+func (aw *asyncWriter) Write(r Record) {
+	//Close() waits for writes in progress, so the queue is read until they are done
+	aw.closing.RLock()
+	defer aw.closing.RUnlock()
+	if aw.isClosed {
+		atomic.AddUint64(&aw.dropped, 1)
+		return
+	}
+	select {
+	case aw.queue <- r:
+		atomic.AddUint64(&aw.queued, 1)
+		return
+	default:
+	}
+
+	//queue is full
+	policy := aw.config.Overflow
+	if policy == OverflowDropBelow {
+		if !aw.config.DropLevel.Enables(r.Level) {
+			policy = OverflowDropNewest
+		} else {
+			policy = OverflowBlock
+		}
+	}
+	switch policy {
+	case OverflowDropNewest:
+		atomic.AddUint64(&aw.dropped, 1)
+	case OverflowDropOldest:
+		for {
+			select {
+			case aw.queue <- r:
+				atomic.AddUint64(&aw.queued, 1)
+				return
+			default:
+			}
+			select {
+			case <-aw.queue:
+				atomic.AddUint64(&aw.dropped, 1)
+				aw.markDone()
+			default:
+			}
+		}
+	default:
+		aw.queue <- r
+		atomic.AddUint64(&aw.queued, 1)
+	}
+} //asyncWriter.Write()
+
+func (aw *asyncWriter) run() {
+	defer close(aw.stopped)
+	for {
+		select {
+		case r := <-aw.queue:
+			aw.write(r)
+			aw.markDone()
+		case <-aw.closed:
+			//write what remains in the queue
+			for {
+				select {
+				case r := <-aw.queue:
+					aw.write(r)
+					aw.markDone()
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// write writes one record and recovers if the writer panics, so later records are still written
+func (aw *asyncWriter) write(r Record) {
+	defer func() {
+		if p := recover(); p != nil {
+			fmt.Fprintf(os.Stderr, "logger: async writer (%T) panic: %v\n", aw.writer, p)
+		}
+	}()
+	aw.writer.Write(r)
+}
+
+// markDone counts a record taken from the queue and wakes up Flush()
+func (aw *asyncWriter) markDone() {
+	aw.mutex.Lock()
+	aw.done++
+	if aw.notify != nil {
+		close(aw.notify)
+		aw.notify = nil
+	}
+	aw.mutex.Unlock()
+}
+
+// Flush waits until all records queued before the call were written
+// then flushes the writer if it can be flushed
+func (aw *asyncWriter) Flush(ctx context.Context) error {
+	if err := aw.flushOwn(ctx); err != nil {
+		return err
+	}
+	if f, ok := aw.writer.(IFlusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// flushOwn waits until all records queued before the call were written
+func (aw *asyncWriter) flushOwn(ctx context.Context) error {
+	target := atomic.LoadUint64(&aw.queued)
+	for {
+		aw.mutex.Lock()
+		if aw.done >= target {
+			aw.mutex.Unlock()
+			break
+		}
+		if aw.notify == nil {
+			aw.notify = make(chan struct{})
+		}
+		notify := aw.notify
+		aw.mutex.Unlock()
+
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+} //asyncWriter.flushOwn()
+
+func (aw *asyncWriter) Close() error {
+	aw.closeOwn()
+	if c, ok := aw.writer.(ICloser); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// closeOwn writes the queued records and stops
+func (aw *asyncWriter) closeOwn() error {
+	aw.closing.Lock()
+	if !aw.isClosed {
+		aw.isClosed = true
+		close(aw.closed)
+	}
+	aw.closing.Unlock()
+	<-aw.stopped
+	return nil
+}
+
+func (aw *asyncWriter) Writers() []IWriter { return []IWriter{aw.writer} }
+
+func (aw *asyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&aw.dropped)
+}
@@ -0,0 +1,125 @@
+package logger_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-msvc/logger"
+)
+
+// gateWriter blocks each write until the gate is opened
+// and signals entered, when not nil, when a write starts waiting
+type gateWriter struct {
+	testWriter
+	gate    chan struct{}
+	entered chan struct{}
+}
+
+func (w *gateWriter) Write(r logger.Record) {
+	if w.entered != nil {
+		w.entered <- struct{}{}
+	}
+	<-w.gate
+	w.testWriter.Write(r)
+}
+
+func TestAsyncWriterFlush(t *testing.T) {
+	w := &testWriter{records: []logger.Record{}}
+	aw := logger.NewAsyncWriter(w, logger.AsyncConfig{})
+	for i := 0; i < 100; i++ {
+		aw.Write(logger.Record{Level: logger.LevelInfo, Message: fmt.Sprintf("%d", i)})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := aw.Flush(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if len(w.records) != 100 || w.records[99].Message != "99" {
+		t.Fatalf("not flushed: %d", len(w.records))
+	}
+	aw.Close()
+	aw.Write(logger.Record{})
+	if aw.Dropped() != 1 || len(w.records) != 100 {
+		t.Fatalf("write after close: dropped=%d records=%d", aw.Dropped(), len(w.records))
+	}
+}
+
+func TestAsyncWriterOverflow(t *testing.T) {
+	tests := []struct {
+		config   logger.AsyncConfig
+		expected []string //messages written
+		dropped  uint64
+	}{
+		{logger.AsyncConfig{QueueSize: 2, Overflow: logger.OverflowDropNewest}, []string{"0", "1", "2"}, 3},
+		{logger.AsyncConfig{QueueSize: 2, Overflow: logger.OverflowDropOldest}, []string{"0", "4", "5"}, 3},
+		{logger.AsyncConfig{QueueSize: 2, Overflow: logger.OverflowDropBelow, DropLevel: logger.LevelWarn}, []string{"0", "1", "2", "4"}, 2},
+	}
+	for index, test := range tests {
+		w := &gateWriter{gate: make(chan struct{}), entered: make(chan struct{}, 10)}
+		aw := logger.NewAsyncWriter(w, test.config)
+		//first record is taken by the background writer that waits for the gate
+		//then the queue fills up with the next 2
+		aw.Write(logger.Record{Level: logger.LevelInfo, Message: "0"})
+		<-w.entered
+		levels := []logger.Level{logger.LevelInfo, logger.LevelInfo, logger.LevelInfo, logger.LevelError, logger.LevelDebug}
+		for i, level := range levels {
+			if i == 3 && test.config.Overflow == logger.OverflowDropBelow {
+				//error record waits for space, made when "0" is written and "1" taken from the queue
+				//"1" then waits at the gate, so the queue is full again for the debug record
+				go func() {
+					w.gate <- struct{}{}
+				}()
+			}
+			aw.Write(logger.Record{Level: level, Message: fmt.Sprintf("%d", i+1)})
+		}
+		close(w.gate)
+		aw.Close()
+		messages := []string{}
+		for _, r := range w.records {
+			messages = append(messages, r.Message)
+		}
+		if fmt.Sprintf("%v", messages) != fmt.Sprintf("%v", test.expected) || aw.Dropped() != test.dropped {
+			t.Fatalf("test[%d]: written %v != %v, dropped %d != %d", index, messages, test.expected, aw.Dropped(), test.dropped)
+		}
+	}
+}
+
+func TestAsyncWriterFlushTimeout(t *testing.T) {
+	w := &gateWriter{gate: make(chan struct{})}
+	aw := logger.NewAsyncWriter(w, logger.AsyncConfig{})
+	aw.Write(logger.Record{})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := aw.Flush(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("flush did not time out: %v", err)
+	}
+	close(w.gate)
+	aw.Close()
+}
+
+// panicMessageWriter panics on records with message "panic"
+type panicMessageWriter struct {
+	testWriter
+}
+
+func (w *panicMessageWriter) Write(r logger.Record) {
+	if r.Message == "panic" {
+		panic("writer failed")
+	}
+	w.testWriter.Write(r)
+}
+
+func TestAsyncWriterPanic(t *testing.T) {
+	w := &panicMessageWriter{}
+	aw := logger.NewAsyncWriter(w, logger.AsyncConfig{})
+	aw.Write(logger.Record{Message: "panic"})
+	aw.Write(logger.Record{Message: "after"})
+	if err := aw.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if len(w.records) != 1 || w.records[0].Message != "after" {
+		t.Fatalf("records after panic: %+v", w.records)
+	}
+}
@@ -49,7 +49,7 @@ func (c caller) String() string {
 // with Function: "github.com/go-msvc/ms_test.TestCaller"
 // return "github.com/go-msvc/ms_test"
 func (c caller) Package() string {
-	if i := strings.LastIndex(c.pkgDotFunc, "."); i >= 0 {
+	if i := pkgDotIndex(c.pkgDotFunc); i >= 0 {
 		return c.pkgDotFunc[:i]
 	}
 	return ""
@@ -57,17 +57,18 @@ func (c caller) Package() string {
 
 // return "github.com/go-msvc/ms_test/my_test.go"
 func (c caller) PackageFile() string {
-	if i := strings.LastIndex(c.pkgDotFunc, "."); i >= 0 {
+	if i := pkgDotIndex(c.pkgDotFunc); i >= 0 {
 		return c.pkgDotFunc[:i] + "/" + path.Base(c.file)
 	}
 	return ""
 }
 
 // with Function: "github.com/go-msvc/ms_test.TestCaller"
-// return "github.com/go-msvc/ms_test"
+// return "TestCaller"
+// methods and closures keep the rest of the name, e.g. "(*Server).Handle" or "TestCaller.func1"
 func (c caller) Function() string {
-	if i := strings.LastIndex(c.pkgDotFunc, "."); i >= 0 {
-		return c.pkgDotFunc[i+1:]
+	if pkgDotIndex(c.pkgDotFunc) >= 0 {
+		return funcName(c.pkgDotFunc)
 	}
 	return ""
 }
@@ -128,6 +129,16 @@ func (caller caller) Format(f fmt.State, c rune) {
 	io.WriteString(f, s)
 } // caller.Format()
 
+// pkgDotIndex returns the index of the '.' after the package in a name reported by func.Name(),
+// which is the first '.' after the last '/', or -1 if there is none
+func pkgDotIndex(name string) int {
+	slash := strings.LastIndex(name, "/")
+	if i := strings.Index(name[slash+1:], "."); i >= 0 {
+		return slash + 1 + i
+	}
+	return -1
+}
+
 // funcName removes the path prefix component of a function's name reported by func.Name().
 func funcName(name string) string {
 	i := strings.LastIndex(name, "/")
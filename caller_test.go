@@ -51,3 +51,22 @@ func TestCaller(t *testing.T) {
 		t.Logf("test[%d] OK: fmt.Sprintf(\"%s\", caller) -> \"%s\"", index, test.format, s)
 	}
 }
+
+type testServer struct{}
+
+func (s *testServer) handle() logger.Caller {
+	return logger.GetCaller(1)
+}
+
+func TestCallerMethod(t *testing.T) {
+	c := (&testServer{}).handle()
+	if c.Package() != "github.com/go-msvc/logger_test" || c.Function() != "(*testServer).handle" {
+		t.Fatalf("Package=%s Function=%s", c.Package(), c.Function())
+	}
+	func() {
+		c = logger.GetCaller(1)
+	}()
+	if c.Package() != "github.com/go-msvc/logger_test" || c.Function() != "TestCallerMethod.func1" {
+		t.Fatalf("Package=%s Function=%s", c.Package(), c.Function())
+	}
+}
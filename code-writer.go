@@ -1,23 +1,70 @@
 package logger
 
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ICodeWriter writes records with levels set for parts of the code
+// when more than one setting matches a record, the most specific one is used:
+// line, then function, then file, then package and then the writer's level
+// and when more than one pattern of the same kind matches, the longest pattern is used
 type ICodeWriter interface {
 	IWriter
-	SetFileLevel(name string, level Level)               //level=Default to delete all file settings
+	SetFileLevel(name string, level Level)               //name or path.Match pattern of Caller.PackageFile(), level=Default to delete all file settings
 	SetFileLineLevel(name string, line int, level Level) //level=Default to delete line setting
+	SetFunctionLevel(name string, level Level)           //Caller.Function(), e.g. "(*Server).Handle", optionally with package "github.com/a/b.(*Server).Handle"
+	SetPackageLevel(name string, level Level)            //Caller.Package(), "github.com/a/b/..." for b and all below it, or a path.Match pattern
+	Rules() []CodeRule                                   //current settings, sorted
+	ClearRules()                                         //delete all settings
+}
+
+// CodeRule is a level set on one of:
+//
+//	a file (File set, Line=0)
+//	a line in a file (File and Line set)
+//	a function (Function set)
+//	a package (Package set)
+type CodeRule struct {
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Function string `json:"function,omitempty"`
+	Package  string `json:"package,omitempty"`
+	Level    Level  `json:"level"`
 }
 
 func NewCodeWriter(w IWriter, l Level) ICodeWriter {
-	return &codeWriter{
-		writer:    w,
-		level:     l,
-		fileLevel: map[string]fileLevel{},
+	cw := &codeWriter{
+		writer: w,
+		level:  l,
 	}
+	cw.rules.Store(&codeRules{
+		fileLevel:     map[string]fileLevel{},
+		functionLevel: map[string]Level{},
+		packageLevel:  map[string]Level{},
+	})
+	return cw
 }
 
+// codeWriter reads the rules without locking
+// changes are made to a copy of the rules under the mutex, then the copy replaces the rules
 type codeWriter struct {
-	writer    IWriter
-	level     Level
-	fileLevel map[string]fileLevel
+	writer IWriter
+	level  Level
+	mutex  sync.Mutex
+	rules  atomic.Value //*codeRules that is never modified once stored
+}
+
+type codeRules struct {
+	fileLevel     map[string]fileLevel //by exact file name
+	fileGlobs     []patternLevel       //longest first
+	functionLevel map[string]Level     //by function name with or without package
+	packageLevel  map[string]Level     //by exact package name
+	packageGlobs  []patternLevel       //longest first
 }
 
 type fileLevel struct {
@@ -25,62 +72,261 @@ type fileLevel struct {
 	lineLevel map[int]Level
 }
 
-func (cw codeWriter) Write(record Record) {
+type patternLevel struct {
+	pattern string
+	level   Level
+}
+
+func (cw *codeWriter) Write(record Record) {
 	//see if should write
-	fileName := record.Caller.PackageFile()
-	fileLevel, ok := cw.fileLevel[fileName]
-	if !ok {
-		//no file entry - use global level
-		if record.Level > cw.level {
-			return //do not write global
-		}
-	} else {
-		//has file entry
-		lineLevel, ok := fileLevel.lineLevel[record.Caller.Line()]
-		if !ok {
-			//no line entry
-			if record.Level > fileLevel.level {
-				return //do not write this file
-			}
-		} else {
-			if record.Level > lineLevel {
-				//file.line has an entry
-				return //do not write this line
+	if !cw.rules.Load().(*codeRules).level(record.Caller, cw.level).Enables(record.Level) {
+		return
+	}
+	cw.writer.Write(record)
+}
+
+func (cw *codeWriter) Flush(ctx context.Context) error {
+	if f, ok := cw.writer.(IFlusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+func (cw *codeWriter) Close() error {
+	if c, ok := cw.writer.(ICloser); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (cw *codeWriter) Writers() []IWriter { return []IWriter{cw.writer} }
+
+// a code writer has nothing of its own to flush or close
+func (cw *codeWriter) flushOwn(ctx context.Context) error { return nil }
+func (cw *codeWriter) closeOwn() error                    { return nil }
+
+// level returns the level of the most specific rule that matches the caller
+func (rules *codeRules) level(c Caller, global Level) Level {
+	if c == nil {
+		return global
+	}
+	fileLevel, hasFile := rules.fileLevel[c.PackageFile()]
+	if hasFile {
+		if lineLevel, ok := fileLevel.lineLevel[c.Line()]; ok {
+			return lineLevel
+		}
+	}
+	if len(rules.functionLevel) > 0 {
+		if level, ok := rules.functionLevel[c.Function()]; ok {
+			return level
+		}
+		if level, ok := rules.functionLevel[c.Package()+"."+c.Function()]; ok {
+			return level
+		}
+	}
+	if hasFile && fileLevel.level != LevelDefault {
+		return fileLevel.level
+	}
+	for _, g := range rules.fileGlobs {
+		if ok, _ := path.Match(g.pattern, c.PackageFile()); ok {
+			return g.level
+		}
+	}
+	if len(rules.packageLevel) > 0 || len(rules.packageGlobs) > 0 {
+		pkg := c.Package()
+		if level, ok := rules.packageLevel[pkg]; ok {
+			return level
+		}
+		for _, g := range rules.packageGlobs {
+			if matchPackage(g.pattern, pkg) {
+				return g.level
 			}
 		}
 	}
-	cw.writer.Write(record)
+	return global
+} //codeRules.level()
+
+// matchPackage matches "a/b/..." with a/b and all packages below it, or a path.Match pattern
+func matchPackage(pattern, pkg string) bool {
+	if prefix := strings.TrimSuffix(pattern, "/..."); prefix != pattern {
+		return pkg == prefix || strings.HasPrefix(pkg, prefix+"/")
+	}
+	ok, _ := path.Match(pattern, pkg)
+	return ok
+}
+
+func isPattern(name string) bool {
+	return strings.ContainsAny(name, "*?[") || strings.HasSuffix(name, "/...")
+}
+
+// update calls change() with a copy of the rules, then stores the copy
+func (cw *codeWriter) update(change func(rules *codeRules)) {
+	cw.mutex.Lock()
+	defer cw.mutex.Unlock()
+	old := cw.rules.Load().(*codeRules)
+	rules := &codeRules{
+		fileLevel:     make(map[string]fileLevel, len(old.fileLevel)),
+		fileGlobs:     append([]patternLevel{}, old.fileGlobs...),
+		functionLevel: make(map[string]Level, len(old.functionLevel)),
+		packageLevel:  make(map[string]Level, len(old.packageLevel)),
+		packageGlobs:  append([]patternLevel{}, old.packageGlobs...),
+	}
+	for name, fl := range old.fileLevel {
+		rules.fileLevel[name] = fl
+	}
+	for name, level := range old.functionLevel {
+		rules.functionLevel[name] = level
+	}
+	for name, level := range old.packageLevel {
+		rules.packageLevel[name] = level
+	}
+	change(rules)
+	cw.rules.Store(rules)
+}
+
+// setPattern adds, replaces or with LevelDefault deletes a pattern, keeping the longest first
+func setPattern(list []patternLevel, pattern string, level Level) []patternLevel {
+	for i, g := range list {
+		if g.pattern == pattern {
+			list = append(list[:i], list[i+1:]...)
+			break
+		}
+	}
+	if level != LevelDefault {
+		list = append(list, patternLevel{pattern: pattern, level: level})
+		sort.SliceStable(list, func(i, j int) bool { return len(list[i].pattern) > len(list[j].pattern) })
+	}
+	return list
+}
+
+// copyLineLevels returns a copy that can be changed without affecting the stored rules
+func (fl fileLevel) copyLineLevels() fileLevel {
+	lineLevel := make(map[int]Level, len(fl.lineLevel))
+	for line, level := range fl.lineLevel {
+		lineLevel[line] = level
+	}
+	fl.lineLevel = lineLevel
+	return fl
 }
 
 func (cw *codeWriter) SetFileLevel(name string, level Level) {
-	if name != "" {
+	if name == "" {
+		return
+	}
+	cw.update(func(rules *codeRules) {
+		if isPattern(name) {
+			rules.fileGlobs = setPattern(rules.fileGlobs, name, level)
+			return
+		}
 		if level == LevelDefault {
-			delete(cw.fileLevel, name)
+			delete(rules.fileLevel, name)
 		} else {
-			fl, ok := cw.fileLevel[name]
+			fl, ok := rules.fileLevel[name]
 			if !ok {
-				fl = fileLevel{level: level, lineLevel: map[int]Level{}}
+				fl = fileLevel{lineLevel: map[int]Level{}}
 			}
-			cw.fileLevel[name] = fl
+			fl.level = level
+			rules.fileLevel[name] = fl
 		}
-	}
+	})
 }
 
 func (cw *codeWriter) SetFileLineLevel(name string, line int, level Level) {
-	if name != "" && line > 0 {
-		fl, ok := cw.fileLevel[name]
+	if name == "" || line <= 0 {
+		return
+	}
+	cw.update(func(rules *codeRules) {
+		fl, ok := rules.fileLevel[name]
 		if !ok {
 			fl = fileLevel{level: LevelDefault, lineLevel: map[int]Level{}}
 		}
+		fl = fl.copyLineLevels()
 		if level == LevelDefault {
 			delete(fl.lineLevel, line)
 		} else {
 			fl.lineLevel[line] = level
 		}
 		if len(fl.lineLevel) == 0 && fl.level == LevelDefault {
-			delete(cw.fileLevel, name) //nothing remain for this file, get rid of it
+			delete(rules.fileLevel, name) //nothing remain for this file, get rid of it
+		} else {
+			rules.fileLevel[name] = fl
+		}
+	})
+}
+
+func (cw *codeWriter) SetFunctionLevel(name string, level Level) {
+	if name == "" {
+		return
+	}
+	cw.update(func(rules *codeRules) {
+		if level == LevelDefault {
+			delete(rules.functionLevel, name)
 		} else {
-			cw.fileLevel[name] = fl
+			rules.functionLevel[name] = level
 		}
+	})
+}
+
+func (cw *codeWriter) SetPackageLevel(name string, level Level) {
+	if name == "" {
+		return
 	}
+	cw.update(func(rules *codeRules) {
+		if isPattern(name) {
+			rules.packageGlobs = setPattern(rules.packageGlobs, name, level)
+		} else if level == LevelDefault {
+			delete(rules.packageLevel, name)
+		} else {
+			rules.packageLevel[name] = level
+		}
+	})
 }
+
+func (cw *codeWriter) ClearRules() {
+	cw.update(func(rules *codeRules) {
+		*rules = codeRules{
+			fileLevel:     map[string]fileLevel{},
+			functionLevel: map[string]Level{},
+			packageLevel:  map[string]Level{},
+		}
+	})
+}
+
+func (cw *codeWriter) Rules() []CodeRule {
+	rules := cw.rules.Load().(*codeRules)
+	list := []CodeRule{}
+	for name, fl := range rules.fileLevel {
+		if fl.level != LevelDefault {
+			list = append(list, CodeRule{File: name, Level: fl.level})
+		}
+		for line, level := range fl.lineLevel {
+			list = append(list, CodeRule{File: name, Line: line, Level: level})
+		}
+	}
+	for _, g := range rules.fileGlobs {
+		list = append(list, CodeRule{File: g.pattern, Level: g.level})
+	}
+	for name, level := range rules.functionLevel {
+		list = append(list, CodeRule{Function: name, Level: level})
+	}
+	for name, level := range rules.packageLevel {
+		list = append(list, CodeRule{Package: name, Level: level})
+	}
+	for _, g := range rules.packageGlobs {
+		list = append(list, CodeRule{Package: g.pattern, Level: g.level})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Function != b.Function {
+			return a.Function < b.Function
+		}
+		return a.Package < b.Package
+	})
+	return list
+} //codeWriter.Rules()
@@ -27,12 +27,14 @@ func TestCodeWriterGlobal(t *testing.T) {
 	test(t, w, l)
 
 	funcName := "test" //where it was writter
+	//records 4 and 5 are written by l.New(l.Name()) in test()
+	subName := l.Name() + "/" + l.Name()
 	w.assert(t, 0, funcName, l.Name(), "DEBUG", "123", map[string]interface{}{})
 	w.assert(t, 1, funcName, l.Name(), "INFO", "123", map[string]interface{}{})
 	w.assert(t, 2, funcName, l.Name(), "ERROR", "123", map[string]interface{}{})
 	w.assert(t, 3, funcName, l.Name(), "DEBUG", "456", map[string]interface{}{"email": "a@b.c"})
-	w.assert(t, 4, funcName, l.Name(), "DEBUG", "789", map[string]interface{}{})
-	w.assert(t, 5, funcName, l.Name(), "DEBUG", "101", map[string]interface{}{"email": "j@k.l"})
+	w.assert(t, 4, funcName, subName, "DEBUG", "789", map[string]interface{}{})
+	w.assert(t, 5, funcName, subName, "DEBUG", "101", map[string]interface{}{"email": "j@k.l"})
 }
 
 func TestCodeWriterFile(t *testing.T) {
@@ -63,10 +65,87 @@ func TestCodeWriterLine(t *testing.T) {
 	test(t, w, l)
 
 	funcName := "test" //where it was writter
+	//records 4 and 5 are written by l.New(l.Name()) in test()
+	subName := l.Name() + "/" + l.Name()
 	w.assert(t, 0, funcName, l.Name(), "DEBUG", "123", map[string]interface{}{})
 	w.assert(t, 1, funcName, l.Name(), "INFO", "123", map[string]interface{}{})
 	w.assert(t, 2, funcName, l.Name(), "ERROR", "123", map[string]interface{}{})
 	w.assert(t, 3, funcName, l.Name(), "DEBUG", "456", map[string]interface{}{"email": "a@b.c"})
 	//w.assert(t, 4, funcName, l.Name(), "DEBUG", "789", map[string]interface{}{"email": "j@k.l"})
-	w.assert(t, 4, funcName, l.Name(), "DEBUG", "101", map[string]interface{}{"email": "j@k.l"})
+	w.assert(t, 4, funcName, subName, "DEBUG", "101", map[string]interface{}{"email": "j@k.l"})
+}
+
+func TestCodeWriterRules(t *testing.T) {
+	cw := logger.NewCodeWriter(&testWriter{}, logger.LevelError)
+	cw.SetFileLevel("a.go", logger.LevelInfo)
+	cw.SetFileLevel("a.go", logger.LevelDebug) //replaces existing level
+	cw.SetFileLineLevel("a.go", 10, logger.LevelTrace)
+	cw.SetFileLineLevel("b.go", 5, logger.LevelWarn)
+	rules := cw.Rules()
+	expected := []logger.CodeRule{
+		{File: "a.go", Level: logger.LevelDebug},
+		{File: "a.go", Line: 10, Level: logger.LevelTrace},
+		{File: "b.go", Line: 5, Level: logger.LevelWarn},
+	}
+	if len(rules) != len(expected) {
+		t.Fatalf("rules: %+v", rules)
+	}
+	for i, r := range expected {
+		if rules[i] != r {
+			t.Fatalf("rule[%d]: %+v != %+v", i, rules[i], r)
+		}
+	}
+
+	cw.SetFileLineLevel("b.go", 5, logger.LevelDefault)
+	if len(cw.Rules()) != 2 {
+		t.Fatalf("rules: %+v", cw.Rules())
+	}
+	cw.ClearRules()
+	if len(cw.Rules()) != 0 {
+		t.Fatalf("rules not cleared: %+v", cw.Rules())
+	}
+}
+
+func TestCodeWriterPrecedence(t *testing.T) {
+	w := &testWriter{records: []logger.Record{}}
+	cw := logger.NewCodeWriter(w, logger.LevelError)
+	l := logger.Named("example.com/precedence").WithLevel(logger.LevelTrace)
+	l.SetWriter(cw)
+	defer l.SetWriter(nil)
+
+	file := "github.com/go-msvc/logger_test/code-writer_test.go"
+	tests := []struct {
+		set      func()
+		expected logger.Level //lowest level logged by precedenceFunc()
+	}{
+		{func() {}, logger.LevelError},
+		{func() { cw.SetPackageLevel("github.com/go-msvc/...", logger.LevelWarn) }, logger.LevelWarn},
+		{func() { cw.SetPackageLevel("github.com/go-msvc/logger_test", logger.LevelInfo) }, logger.LevelInfo},
+		{func() { cw.SetFileLevel("github.com/go-msvc/*/code-writer_*.go", logger.LevelWarn) }, logger.LevelWarn},
+		{func() { cw.SetFileLevel(file, logger.LevelDebug) }, logger.LevelDebug},
+		{func() { cw.SetFunctionLevel("github.com/go-msvc/logger_test.precedenceFunc", logger.LevelInfo) }, logger.LevelInfo},
+		{func() { cw.SetFunctionLevel("precedenceFunc", logger.LevelTrace) }, logger.LevelTrace},
+		{func() { cw.SetFileLineLevel(file, precedenceLine, logger.LevelError) }, logger.LevelError},
+		{func() { cw.SetFileLineLevel(file, precedenceLine, logger.LevelDefault) }, logger.LevelTrace},
+		{func() { cw.SetFunctionLevel("precedenceFunc", logger.LevelDefault) }, logger.LevelInfo},
+		{func() { cw.ClearRules() }, logger.LevelError},
+	}
+	for index, test := range tests {
+		test.set()
+		w.Reset()
+		precedenceFunc(l)
+		if len(w.records) == 0 || w.records[len(w.records)-1].Level != test.expected {
+			t.Fatalf("test[%d]: wrong records for %s: %+v", index, test.expected, w.records)
+		}
+	}
+}
+
+// precedenceLine is the line of the log call in precedenceFunc(), set when it runs
+var precedenceLine int
+
+func precedenceFunc(l logger.Logger) {
+	for _, level := range []logger.Level{logger.LevelError, logger.LevelWarn, logger.LevelInfo, logger.LevelDebug, logger.LevelTrace} {
+		precedenceLine = logger.GetCaller(1).Line() + 1
+		l.Log(level, "x")
+	}
 }
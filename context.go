@@ -0,0 +1,176 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+type contextKey struct{}
+
+// WithContext returns a copy of ctx that holds l, to get it back with FromContext()
+func WithContext(ctx context.Context, l Logger) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger stored with WithContext()
+// or New() for the package of the caller when ctx does not have a logger
+func FromContext(ctx context.Context) Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(Logger); ok {
+			return l
+		}
+	}
+	return top.New(GetCaller(2).Package())
+}
+
+// ContextField extracts a value from a context, returning false when ctx does not have it
+type ContextField func(ctx context.Context) (interface{}, bool)
+
+// RegisterContextKey makes the XxxCtx() log methods add the value of ctx.Value(key) as data with the name
+// e.g. RegisterContextKey("request_id", requestIDKey{})
+func RegisterContextKey(name string, key interface{}) {
+	RegisterContextField(name, func(ctx context.Context) (interface{}, bool) {
+		v := ctx.Value(key)
+		return v, v != nil
+	})
+}
+
+// RegisterContextField makes the XxxCtx() log methods add the value returned by f as data with the name
+// registering a name again replaces it, and a nil f removes it
+func RegisterContextField(name string, f ContextField) {
+	contextFieldsMutex.Lock()
+	defer contextFieldsMutex.Unlock()
+	old, _ := contextFields.Load().([]contextField)
+	fields := make([]contextField, 0, len(old)+1)
+	for _, cf := range old {
+		if cf.name != name {
+			fields = append(fields, cf)
+		}
+	}
+	if f != nil {
+		fields = append(fields, contextField{name: name, f: f})
+	}
+	contextFields.Store(fields)
+}
+
+// ContextDeadline is a ContextField with the deadline of the context, e.g.
+// RegisterContextField("deadline", ContextDeadline)
+func ContextDeadline(ctx context.Context) (interface{}, bool) {
+	deadline, ok := ctx.Deadline()
+	return deadline, ok
+}
+
+type contextField struct {
+	name string
+	f    ContextField
+}
+
+// contextFields is replaced with a new list on each change so it is read without locking
+var (
+	contextFieldsMutex sync.Mutex
+	contextFields      atomic.Value //[]contextField
+)
+
+// withContext returns a copy of the logger with the registered context values added to its data
+// and the header from ContextWithHeader() merged into its header
+func (l logger) withContext(ctx context.Context) logger {
+	if ctx == nil {
+		return l
+	}
+	if h, ok := ctx.Value(headerKey{}).(Header); ok {
+		l.header = l.header.Merge(h)
+	}
+	fields, _ := contextFields.Load().([]contextField)
+	var data map[string]interface{}
+	for _, cf := range fields {
+		v, ok := cf.f(ctx)
+		if !ok {
+			continue
+		}
+		if data == nil {
+			data = make(map[string]interface{}, len(l.data)+len(fields))
+			for n, v := range l.data {
+				data[n] = v
+			}
+			l.keys = l.keys[:len(l.keys):len(l.keys)]
+		}
+		if _, ok := data[cf.name]; !ok {
+			l.keys = append(l.keys, cf.name)
+		}
+		data[cf.name] = v
+	}
+	if data != nil {
+		l.data = data
+	}
+	return l
+} //logger.withContext()
+
+func (l logger) Ctx(ctx context.Context) Logger {
+	return l.withContext(ctx)
+}
+
+func (l logger) LogCtx(ctx context.Context, level Level, msg string, fields ...Field) {
+	l.logCtx(4, ctx, level, msg, fields)
+}
+
+func (l logger) ErrorCtx(ctx context.Context, msg string, fields ...Field) {
+	l.logCtx(4, ctx, LevelError, msg, fields)
+}
+
+func (l logger) WarnCtx(ctx context.Context, msg string, fields ...Field) {
+	l.logCtx(4, ctx, LevelWarn, msg, fields)
+}
+
+func (l logger) InfoCtx(ctx context.Context, msg string, fields ...Field) {
+	l.logCtx(4, ctx, LevelInfo, msg, fields)
+}
+
+func (l logger) DebugCtx(ctx context.Context, msg string, fields ...Field) {
+	l.logCtx(4, ctx, LevelDebug, msg, fields)
+}
+
+func (l logger) TraceCtx(ctx context.Context, msg string, fields ...Field) {
+	l.logCtx(4, ctx, LevelTrace, msg, fields)
+}
+
+func (l logger) LogfCtx(ctx context.Context, level Level, format string, args ...interface{}) {
+	l.logfCtx(4, ctx, level, format, args...)
+}
+
+func (l logger) ErrorfCtx(ctx context.Context, format string, args ...interface{}) {
+	l.logfCtx(4, ctx, LevelError, format, args...)
+}
+
+func (l logger) WarnfCtx(ctx context.Context, format string, args ...interface{}) {
+	l.logfCtx(4, ctx, LevelWarn, format, args...)
+}
+
+func (l logger) InfofCtx(ctx context.Context, format string, args ...interface{}) {
+	l.logfCtx(4, ctx, LevelInfo, format, args...)
+}
+
+func (l logger) DebugfCtx(ctx context.Context, format string, args ...interface{}) {
+	l.logfCtx(4, ctx, LevelDebug, format, args...)
+}
+
+func (l logger) TracefCtx(ctx context.Context, format string, args ...interface{}) {
+	l.logfCtx(4, ctx, LevelTrace, format, args...)
+}
+
+// logCtx only extracts the context values when the record will be written
+func (l logger) logCtx(depth int, ctx context.Context, level Level, msg string, fields []Field) {
+	if l.Level().Enables(level) {
+		l.withContext(ctx).log(depth, level, msg, fields)
+	}
+}
+
+func (l logger) logfCtx(depth int, ctx context.Context, level Level, format string, args ...interface{}) {
+	if l.Level().Enables(level) {
+		l.withContext(ctx).log(depth, level, fmt.Sprintf(format, args...), nil)
+	}
+}
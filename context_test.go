@@ -0,0 +1,56 @@
+package logger_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-msvc/logger"
+)
+
+type requestIDKey struct{}
+
+func TestContextLogger(t *testing.T) {
+	l := logger.Named("example.com/ctx").With("a", 1)
+	ctx := logger.WithContext(context.Background(), l)
+	if got := logger.FromContext(ctx); got.Name() != "example.com/ctx" {
+		t.Fatalf("from context: %s", got.Name())
+	}
+	//fallback to the package of the caller
+	if got := logger.FromContext(context.Background()); got.Name() != "github.com/go-msvc/logger_test" {
+		t.Fatalf("fallback: %s", got.Name())
+	}
+}
+
+func TestContextFields(t *testing.T) {
+	logger.RegisterContextKey("request_id", requestIDKey{})
+	logger.RegisterContextField("deadline", logger.ContextDeadline)
+	defer logger.RegisterContextField("request_id", nil)
+	defer logger.RegisterContextField("deadline", nil)
+
+	w := &testWriter{records: []logger.Record{}}
+	l := logger.Named("example.com/ctx/fields").With("a", 1)
+	l.SetWriter(w)
+	defer l.SetWriter(nil)
+
+	deadline := time.Now().Add(time.Hour)
+	ctx, cancel := context.WithDeadline(context.WithValue(context.Background(), requestIDKey{}, "r1"), deadline)
+	defer cancel()
+
+	l.ErrorCtx(ctx, "one")
+	l.ErrorfCtx(context.Background(), "two %d", 2)
+	l.Ctx(ctx).Errorf("three")
+	l.DebugfCtx(ctx, "not written")
+	l.Error("four")
+
+	w.assert(t, 0, "TestContextFields", l.Name(), "ERROR", "one", map[string]interface{}{"a": 1, "request_id": "r1", "deadline": deadline})
+	w.assert(t, 1, "TestContextFields", l.Name(), "ERROR", "two 2", map[string]interface{}{"a": 1})
+	w.assert(t, 2, "TestContextFields", l.Name(), "ERROR", "three", map[string]interface{}{"a": 1, "request_id": "r1", "deadline": deadline})
+	w.assert(t, 3, "TestContextFields", l.Name(), "ERROR", "four", map[string]interface{}{"a": 1})
+	if len(w.records) != 4 || len(w.records[1].Data) != 1 || len(w.records[3].Data) != 1 {
+		t.Fatalf("%d records", len(w.records))
+	}
+	if keys := w.records[0].Keys; len(keys) != 3 || keys[0] != "a" || keys[1] != "request_id" || keys[2] != "deadline" {
+		t.Fatalf("keys: %v", keys)
+	}
+}
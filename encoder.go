@@ -0,0 +1,156 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// IEncoder formats a record as one line of output (including the line terminator)
+// encoders are used by writers that write to a stream, e.g. NewJSONWriter()
+type IEncoder interface {
+	Encode(buf *bytes.Buffer, r Record)
+}
+
+// NewStreamWriter makes a writer that encodes each record and writes it to w
+// each record is encoded in a buffer then written with a single call to w.Write()
+// so that concurrent loggers do not mix their lines
+func NewStreamWriter(w io.Writer, e IEncoder) IWriter {
+	return &streamWriter{
+		w:       w,
+		encoder: e,
+	}
+}
+
+type streamWriter struct {
+	sync.Mutex
+	w       io.Writer
+	encoder IEncoder
+}
+
+var bufferPool = sync.Pool{
+	New: func() interface{} { return &bytes.Buffer{} },
+}
+
+func (sw *streamWriter) Write(r Record) {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	sw.encoder.Encode(buf, r)
+
+	sw.Lock()
+	sw.w.Write(buf.Bytes())
+	sw.Unlock()
+	bufferPool.Put(buf)
+}
+
+// Flush flushes w if it is buffered, e.g. a bufio.Writer
+func (sw *streamWriter) Flush(ctx context.Context) error {
+	if f, ok := sw.w.(interface{ Flush() error }); ok {
+		sw.Lock()
+		defer sw.Unlock()
+		return f.Flush()
+	}
+	return nil
+}
+
+// Close closes w if it can be closed, except for stdout and stderr
+func (sw *streamWriter) Close() error {
+	if sw.w == os.Stdout || sw.w == os.Stderr {
+		return nil
+	}
+	if c, ok := sw.w.(io.Closer); ok {
+		sw.Lock()
+		defer sw.Unlock()
+		return c.Close()
+	}
+	return nil
+}
+
+// recordName returns the full name of the logger that wrote the record
+func recordName(r Record) string {
+	if r.Logger == nil {
+		return ""
+	}
+	return strings.Join(r.Logger.Names(), "/")
+}
+
+// dataNames returns the names in r.Data in the order they were added
+// or in sorted order when sorted is true or the order is not known
+// names that are replaced by a field are left out, see recordFields()
+func dataNames(r Record, sorted bool) []string {
+	var names []string
+	if !sorted && len(r.Keys) == len(r.Data) {
+		names = r.Keys
+	} else {
+		names = make([]string, 0, len(r.Data))
+		for n := range r.Data {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+	}
+	for i, n := range names {
+		if hasField(r.Fields, n) {
+			//copy only when a name is replaced
+			list := append(make([]string, 0, len(names)-1), names[:i]...)
+			for _, n := range names[i+1:] {
+				if !hasField(r.Fields, n) {
+					list = append(list, n)
+				}
+			}
+			return list
+		}
+	}
+	return names
+}
+
+// recordData returns r.Data without the names that are replaced by a field
+func recordData(r Record) map[string]interface{} {
+	replaced := false
+	for n := range r.Data {
+		if hasField(r.Fields, n) {
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		return r.Data
+	}
+	data := make(map[string]interface{}, len(r.Data))
+	for n, v := range r.Data {
+		if !hasField(r.Fields, n) {
+			data[n] = v
+		}
+	}
+	return data
+}
+
+// recordFields returns r.Fields without the fields that are replaced by a later field with the same name
+// so that the last value of a name wins, like the last With() of a name replaces the value in Data
+func recordFields(r Record) []Field {
+	for i, f := range r.Fields {
+		if hasField(r.Fields[i+1:], f.name) {
+			//copy only when a name is repeated
+			list := make([]Field, 0, len(r.Fields)-1)
+			for j, f := range r.Fields {
+				if !hasField(r.Fields[j+1:], f.name) {
+					list = append(list, f)
+				}
+			}
+			return list
+		}
+	}
+	return r.Fields
+}
+
+func hasField(fields []Field, name string) bool {
+	for _, f := range fields {
+		if f.name == name {
+			return true
+		}
+	}
+	return false
+}
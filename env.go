@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// environment variables read by ConfigureFromEnv()
+const (
+	EnvLevel  = "LOG_LEVEL"  //global level, e.g. "info"
+	EnvLevels = "LOG_LEVELS" //comma separated name=level, e.g. "github.com/acme/*=debug,github.com/go-msvc/logger/fakelib=info"
+	EnvFormat = "LOG_FORMAT" //"text"(default), "json" or "logfmt"
+	EnvOutput = "LOG_OUTPUT" //"stderr"(default), "stdout" or "file:/path/to/file"
+)
+
+// ConfigureFromEnv applies the LOG_xxx environment variables
+// to the global writer and the levels of named loggers
+// all valid entries are applied, and the invalid entries are returned in the error
+//
+// it is not called when the package is imported, so libraries that import logger have no
+// side effects. A program applies the environment without code in main() with a blank import:
+//
+//	import _ "github.com/go-msvc/logger/envconfig"
+//
+// or calls it itself at the start of main(), e.g.
+//
+//	if err := logger.ConfigureFromEnv(); err != nil {
+//		fmt.Fprintln(os.Stderr, err)
+//	}
+func ConfigureFromEnv() error {
+	errs := configErrors{}
+
+	format := os.Getenv(EnvFormat)
+	output := os.Getenv(EnvOutput)
+	if format != "" || output != "" {
+		if w, err := envWriter(format, output); err != nil {
+			errs = append(errs, err)
+		} else {
+			SetGlobalWriter(w)
+		}
+	}
+
+	if s := os.Getenv(EnvLevel); s != "" {
+		if level, err := ParseLevel(s); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", EnvLevel, err))
+		} else {
+			SetGlobalLevel(level)
+		}
+	}
+
+	for _, entry := range strings.Split(os.Getenv(EnvLevels), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if err := applyLevelEntry(entry); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", EnvLevels, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+} //ConfigureFromEnv()
+
+// applyLevelEntry sets the level for one "name=level" entry
+// name may end with "/*" or "/..." to make it clear the level applies to all names below it,
+// which is what a level set on a named logger does anyway
+func applyLevelEntry(entry string) error {
+	parts := strings.SplitN(entry, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("%q is not name=level", entry)
+	}
+	name := strings.TrimSpace(parts[0])
+	name = strings.TrimSuffix(name, "...")
+	name = strings.TrimSuffix(name, "*")
+	name = strings.TrimSuffix(name, "/")
+	if strings.ContainsAny(name, "*?[") {
+		return fmt.Errorf("%q: only a trailing /* or /... is supported in the name", entry)
+	}
+	level, err := ParseLevel(parts[1])
+	if err != nil {
+		return fmt.Errorf("%q: %v", entry, err)
+	}
+	if name == "" {
+		SetGlobalLevel(level)
+	} else {
+		Named(name).SetLevel(level)
+	}
+	return nil
+} //applyLevelEntry()
+
+// envWriter makes the writer for LOG_FORMAT and LOG_OUTPUT
+func envWriter(format, output string) (IWriter, error) {
+	var e IEncoder
+	switch strings.ToLower(format) {
+	case "", "text":
+		e, _ = NewTextEncoder(DefaultTextLayout)
+	case "json":
+		e = NewJSONEncoder(JSONConfig{})
+	case "logfmt":
+		e = NewLogfmtEncoder(LogfmtConfig{})
+	default:
+		return nil, fmt.Errorf("%s: %q is not text, json or logfmt", EnvFormat, format)
+	}
+
+	switch {
+	case output == "" || output == "stderr":
+		return NewStreamWriter(os.Stderr, e), nil
+	case output == "stdout":
+		return NewStreamWriter(os.Stdout, e), nil
+	case strings.HasPrefix(output, "file:"):
+		f, err := os.OpenFile(strings.TrimPrefix(output, "file:"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", EnvOutput, err)
+		}
+		return NewStreamWriter(f, e), nil
+	}
+	return nil, fmt.Errorf("%s: %q is not stderr, stdout or file:<path>", EnvOutput, output)
+} //envWriter()
+
+// configErrors reports all invalid entries in the configuration
+type configErrors []error
+
+func (errs configErrors) Error() string {
+	s := make([]string, len(errs))
+	for i, err := range errs {
+		s[i] = err.Error()
+	}
+	return "invalid log config: " + strings.Join(s, "; ")
+}
@@ -0,0 +1,63 @@
+package logger_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-msvc/logger"
+)
+
+func TestConfigureFromEnv(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "test.log")
+
+	env := map[string]string{
+		logger.EnvLevel:  "warn",
+		logger.EnvLevels: "example.net/acme/*=debug, example.net/other/lib=info,bad,example.net/x=loud,example.net/*/y=info",
+		logger.EnvFormat: "json",
+		logger.EnvOutput: "file:" + fileName,
+	}
+	for n, v := range env {
+		os.Setenv(n, v)
+		n := n
+		t.Cleanup(func() { os.Unsetenv(n) })
+	}
+	t.Cleanup(func() {
+		logger.SetGlobalWriter(nil)
+		logger.SetGlobalLevel(logger.LevelError)
+		logger.Named("example.net/acme").ResetLevel()
+		logger.Named("example.net/other/lib").ResetLevel()
+	})
+
+	err := logger.ConfigureFromEnv()
+	if err == nil {
+		t.Fatalf("invalid entries not reported")
+	}
+	t.Logf("err: %v", err)
+	for _, s := range []string{`"bad"`, `"example.net/x=loud"`, `"example.net/*/y=info"`} {
+		if !strings.Contains(err.Error(), s) {
+			t.Fatalf("%s not reported in: %v", s, err)
+		}
+	}
+
+	levels := map[string]logger.Level{
+		"example.net/acme/svc/db": logger.LevelDebug,
+		"example.net/other/lib":   logger.LevelInfo,
+		"example.net/other":       logger.LevelWarn,
+	}
+	for name, level := range levels {
+		if l := logger.Named(name).Level(); l != level {
+			t.Fatalf("%s level=%s != %s", name, l, level)
+		}
+	}
+
+	logger.Named("example.net/acme/svc").Debugf("hello")
+	logger.SetGlobalWriter(nil)
+	data, _ := os.ReadFile(fileName)
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err != nil || obj["msg"] != "hello" || obj["logger"] != "example.net/acme/svc" {
+		t.Fatalf("not logged to file: %v: %s", err, data)
+	}
+}
@@ -0,0 +1,22 @@
+// Package envconfig applies the LOG_xxx environment variables when the program starts
+// so that levels and the writer can change per deployment without code changes
+//
+// a program opts in with a blank import, usually in the file with main():
+//
+//	import _ "github.com/go-msvc/logger/envconfig"
+//
+// libraries should not import it, see logger.ConfigureFromEnv()
+package envconfig
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-msvc/logger"
+)
+
+func init() {
+	if err := logger.ConfigureFromEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "logger: %v\n", err)
+	}
+}
@@ -0,0 +1,30 @@
+package envconfig_test
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/go-msvc/logger"
+	_ "github.com/go-msvc/logger/envconfig"
+)
+
+func TestEnvConfig(t *testing.T) {
+	if os.Getenv("ENVCONFIG_TEST") == "1" {
+		//the environment was applied by the import, before the test runs
+		if l := logger.Named("example.net/envconfig").Level(); l != logger.LevelDebug {
+			t.Fatalf("level %s", l)
+		}
+		return
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestEnvConfig$")
+	cmd.Env = append(os.Environ(), "ENVCONFIG_TEST=1", "LOG_LEVELS=example.net/envconfig=debug,bad")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "logger: ") || !strings.Contains(string(out), "bad") {
+		t.Fatalf("invalid entry not reported: %s", out)
+	}
+}
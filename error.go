@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ILogFields is implemented by errors that add fields to the record they are logged with
+// e.g. an HTTP client error returning {"status": 503, "url": "..."}
+type ILogFields interface {
+	LogFields() map[string]interface{}
+}
+
+// ErrorInfo is how writers describe an error logged with WithError() or Errorw()
+type ErrorInfo struct {
+	Message string                 `json:"message"`
+	Type    string                 `json:"type"`             //concrete type, e.g. "*fs.PathError"
+	Chain   []ErrorInfo            `json:"chain,omitempty"`  //errors it wraps, depth first, without their chain and fields
+	Fields  map[string]interface{} `json:"fields,omitempty"` //from all errors in the chain that implement ILogFields, outer errors first
+}
+
+// maxErrorChain limits the errors listed in a chain, in case errors wrap each other in a cycle
+const maxErrorChain = 32
+
+// GetErrorInfo describes err and the errors it wraps with Unwrap() error or Unwrap() []error
+// it returns nil when err is nil
+func GetErrorInfo(err error) *ErrorInfo {
+	if err == nil {
+		return nil
+	}
+	info := &ErrorInfo{
+		Message: err.Error(),
+		Type:    fmt.Sprintf("%T", err),
+	}
+	info.addFields(err)
+	for _, e := range unwrapErrors(err, nil) {
+		info.Chain = append(info.Chain, ErrorInfo{Message: e.Error(), Type: fmt.Sprintf("%T", e)})
+		info.addFields(e)
+	}
+	return info
+}
+
+// unwrapErrors appends the errors wrapped by err, depth first
+func unwrapErrors(err error, list []error) []error {
+	var wrapped []error
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		wrapped = u.Unwrap()
+	default:
+		if e := errors.Unwrap(err); e != nil {
+			wrapped = []error{e}
+		}
+	}
+	for _, e := range wrapped {
+		if e == nil || len(list) >= maxErrorChain {
+			continue
+		}
+		list = append(list, e)
+		list = unwrapErrors(e, list)
+	}
+	return list
+}
+
+// addFields adds the fields of err that are not yet set
+func (info *ErrorInfo) addFields(err error) {
+	lf, ok := err.(ILogFields)
+	if !ok {
+		return
+	}
+	for n, v := range lf.LogFields() {
+		if info.Fields == nil {
+			info.Fields = map[string]interface{}{}
+		}
+		if _, ok := info.Fields[n]; !ok {
+			info.Fields[n] = v
+		}
+	}
+}
+
+// fieldNames returns the names of the fields in sorted order
+func (info *ErrorInfo) fieldNames() []string {
+	names := make([]string, 0, len(info.Fields))
+	for n := range info.Fields {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// chainString returns the chain as "<type>: <message>" separated by "; "
+func (info *ErrorInfo) chainString() string {
+	s := make([]string, len(info.Chain))
+	for i, c := range info.Chain {
+		s[i] = c.Type + ": " + c.Message
+	}
+	return strings.Join(s, "; ")
+}
+
+// fields lists the error as key/value text, e.g. for logfmt:
+// error=<message> error.type=<type> error.chain=<chain> error.<field>=<value>
+func (info *ErrorInfo) fields() []headerField {
+	if info == nil {
+		return nil
+	}
+	list := []headerField{
+		{name: "error", value: info.Message},
+		{name: "error.type", value: info.Type},
+	}
+	if len(info.Chain) > 0 {
+		list = append(list, headerField{name: "error.chain", value: info.chainString()})
+	}
+	for _, n := range info.fieldNames() {
+		list = append(list, headerField{name: "error." + n, value: dataValueString(info.Fields[n])})
+	}
+	return list
+}
+
+// String returns the error as key=value, like the logfmt writer writes it
+func (info *ErrorInfo) String() string {
+	buf := bytes.Buffer{}
+	for i, f := range info.fields() {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		appendLogfmtKey(&buf, f.name)
+		buf.WriteByte('=')
+		appendLogfmtValue(&buf, f.value)
+	}
+	return buf.String()
+}
@@ -0,0 +1,100 @@
+package logger_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-msvc/logger"
+)
+
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e httpError) Error() string { return fmt.Sprintf("http %d: %v", e.status, e.err) }
+func (e httpError) Unwrap() error { return e.err }
+
+func (e httpError) LogFields() map[string]interface{} {
+	return map[string]interface{}{"status": e.status}
+}
+
+// joinError wraps more than one error like errors.Join()
+type joinError []error
+
+func (e joinError) Error() string   { return "joined" }
+func (e joinError) Unwrap() []error { return e }
+
+func TestErrorInfo(t *testing.T) {
+	if logger.GetErrorInfo(nil) != nil {
+		t.Fatalf("info for nil")
+	}
+	_, pathErr := os.Open("/does/not/exist")
+	err := fmt.Errorf("load: %w", joinError{httpError{status: 503, err: pathErr}, fmt.Errorf("other")})
+	info := logger.GetErrorInfo(err)
+	if info.Message != err.Error() || info.Type != "*fmt.wrapError" {
+		t.Fatalf("info: %+v", info)
+	}
+	types := []string{}
+	for _, c := range info.Chain {
+		types = append(types, c.Type)
+	}
+	if strings.Join(types, ",") != "logger_test.joinError,logger_test.httpError,*fs.PathError,syscall.Errno,*errors.errorString" {
+		t.Fatalf("chain: %v", types)
+	}
+	if len(info.Fields) != 1 || info.Fields["status"] != 503 {
+		t.Fatalf("fields: %v", info.Fields)
+	}
+}
+
+func TestWithError(t *testing.T) {
+	w := &testWriter{records: []logger.Record{}}
+	l := logger.Named("example.com/error")
+	l.SetWriter(w)
+	defer l.SetWriter(nil)
+
+	err := httpError{status: 404, err: fmt.Errorf("not found")}
+	l.WithError(err).With("error", "data").Error("one")
+	l.Errorw(err, "two")
+	l.Error("three")
+	if len(w.records) != 3 || w.records[0].Error != err || w.records[1].Error != err || w.records[2].Error != nil {
+		t.Fatalf("records: %+v", w.records)
+	}
+	w.assert(t, 1, "TestWithError", l.Name(), "ERROR", "two", nil)
+
+	r := w.records[0]
+	r.Caller = nil
+	r.Logger = nil
+	encode := func(e logger.IEncoder) string {
+		buf := bytes.NewBuffer(nil)
+		e.Encode(buf, r)
+		return strings.TrimSuffix(buf.String(), "\n")
+	}
+	if s := encode(logger.NewJSONEncoder(logger.JSONConfig{TimeKey: "-", LevelKey: "-", NameKey: "-"})); s != `{"msg":"one","error":{"message":"http 404: not found","type":"logger_test.httpError","chain":[{"message":"not found","type":"*errors.errorString"}],"fields":{"status":404}},"data.error":"data"}` {
+		t.Fatalf("json: %s", s)
+	}
+	if s := encode(logger.NewLogfmtEncoder(logger.LogfmtConfig{})); !strings.HasSuffix(s, ` msg=one error="http 404: not found" error.type=logger_test.httpError error.chain="*errors.errorString: not found" error.status=404 data.error=data`) {
+		t.Fatalf("logfmt: %s", s)
+	}
+	text, _ := logger.NewTextEncoder("{{.Message}} {{.ErrorInfo.Type}}")
+	if s := encode(text); s != "one logger_test.httpError" {
+		t.Fatalf("text: %s", s)
+	}
+}
+
+func TestWithErrorStack(t *testing.T) {
+	w := &testWriter{records: []logger.Record{}}
+	l := logger.Named("example.com/error/stack")
+	l.SetWriter(w)
+	defer l.SetWriter(nil)
+	l.SetStackLevel(logger.LevelError)
+	defer l.SetStackLevel(logger.LevelDefault)
+
+	l.Errorw(fmt.Errorf("wrapped: %w", newStackError()), "failed")
+	if len(w.records) != 1 || len(w.records[0].ErrorStack) == 0 || w.records[0].ErrorStack[0].Function() != "TestWithErrorStack" {
+		t.Fatalf("records: %+v", w.records)
+	}
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"sort"
+	"time"
+)
+
+// Escalation is a level set temporarily with SetLevelFor()
+type Escalation struct {
+	Name     string    `json:"name"`
+	Level    Level     `json:"level"`
+	Previous Level     `json:"previous"` //level restored at Until (LevelDefault when inherited from the parent)
+	Until    time.Time `json:"until"`
+}
+
+type escalation struct {
+	level    Level
+	previous Level
+	until    time.Time
+	stop     func() bool //stops the timer that ends the escalation
+}
+
+// the clock used by escalations, which tests replace to end escalations without waiting
+var (
+	timeNow       = time.Now
+	timeAfterFunc = func(d time.Duration, f func()) (stop func() bool) {
+		return time.AfterFunc(d, f).Stop
+	}
+)
+
+// setLevelFor sets own level until d passed, then restores the level from before the first active escalation
+func (l *named) setLevelFor(newLevel Level, d time.Duration) {
+	l.updateLevel(func() {
+		previous := l.ownLevel
+		if l.escalation != nil {
+			previous = l.escalation.previous
+			l.stopEscalation()
+		}
+		e := &escalation{
+			level:    newLevel,
+			previous: previous,
+			until:    timeNow().Add(d),
+		}
+		e.stop = timeAfterFunc(d, func() { l.endEscalation(e) })
+		l.escalation = e
+		l.ownLevel = newLevel
+	})
+}
+
+// endEscalation restores the level when e is still the active escalation
+func (l *named) endEscalation(e *escalation) {
+	l.updateLevel(func() {
+		if l.escalation == e {
+			l.escalation = nil
+			l.ownLevel = e.previous
+		}
+	})
+}
+
+// stopEscalation cancels the active escalation without restoring its level
+// must be called with treeMutex locked
+func (l *named) stopEscalation() {
+	if l.escalation != nil {
+		l.escalation.stop()
+		l.escalation = nil
+	}
+}
+
+// Escalations lists the active temporary levels set with SetLevelFor(), sorted by name
+func Escalations() []Escalation {
+	list := []Escalation{}
+	treeMutex.RLock()
+	top.escalations(&list)
+	treeMutex.RUnlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// escalations adds active escalations of l and its subs to the list
+// must be called with treeMutex locked
+func (l *named) escalations(list *[]Escalation) {
+	if e := l.escalation; e != nil {
+		*list = append(*list, Escalation{Name: l.name, Level: e.level, Previous: e.previous, Until: e.until})
+	}
+	for _, sub := range l.subs {
+		sub.escalations(list)
+	}
+}
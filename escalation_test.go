@@ -0,0 +1,67 @@
+package logger_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-msvc/logger"
+)
+
+func TestSetLevelFor(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	advance, restore := logger.FakeEscalationClock(start)
+	defer restore()
+	parent := logger.Named("example.com/escalate")
+	sub := logger.Named("example.com/escalate/sub")
+	parent.SetLevel(logger.LevelInfo)
+
+	parent.SetLevelFor(logger.LevelDebug, 50*time.Millisecond)
+	if parent.Level() != logger.LevelDebug || sub.Level() != logger.LevelDebug {
+		t.Fatalf("not escalated: %s %s", parent.Level(), sub.Level())
+	}
+	//overlapping escalation replaces level and end time, but restores the original level
+	advance(20 * time.Millisecond)
+	parent.SetLevelFor(logger.LevelTrace, 100*time.Millisecond)
+	list := logger.Escalations()
+	if len(list) != 1 || list[0].Name != "example.com/escalate" || list[0].Level != logger.LevelTrace || list[0].Previous != logger.LevelInfo ||
+		!list[0].Until.Equal(start.Add(120*time.Millisecond)) {
+		t.Fatalf("escalations: %+v", list)
+	}
+	advance(50 * time.Millisecond) //first escalation would have ended
+	if sub.Level() != logger.LevelTrace {
+		t.Fatalf("ended too soon: %s", sub.Level())
+	}
+	advance(50 * time.Millisecond)
+	if parent.Level() != logger.LevelInfo || sub.Level() != logger.LevelInfo || len(logger.Escalations()) != 0 {
+		t.Fatalf("not restored: %s %s %+v", parent.Level(), sub.Level(), logger.Escalations())
+	}
+
+	//SetLevel cancels the restore
+	parent.SetLevelFor(logger.LevelDebug, 20*time.Millisecond)
+	parent.SetLevel(logger.LevelWarn)
+	advance(50 * time.Millisecond)
+	if parent.Level() != logger.LevelWarn {
+		t.Fatalf("SetLevel overwritten: %s", parent.Level())
+	}
+
+	//through admin handler
+	h := logger.NewAdminHandler(nil)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"example.com/escalate","level":"debug","for":"1h"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	if res.Code != http.StatusNoContent || sub.Level() != logger.LevelDebug {
+		t.Fatalf("admin: %d %s", res.Code, res.Body.String())
+	}
+	res = httptest.NewRecorder()
+	h.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/escalations", nil))
+	list = nil
+	if err := json.Unmarshal(res.Body.Bytes(), &list); err != nil || len(list) != 1 || list[0].Previous != logger.LevelWarn {
+		t.Fatalf("admin escalations: %v %s", err, res.Body.String())
+	}
+	parent.SetLevel(logger.LevelDefault)
+}
@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// FakeEscalationClock makes escalations use a fake time that starts at start
+// advance moves the time forward and ends the escalations that are due
+// restore puts back the real clock
+func FakeEscalationClock(start time.Time) (advance func(d time.Duration), restore func()) {
+	type fakeTimer struct {
+		at    time.Time
+		f     func()
+		ended bool
+	}
+	var (
+		mutex  sync.Mutex
+		now    = start
+		timers []*fakeTimer
+	)
+	timeNow = func() time.Time {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return now
+	}
+	timeAfterFunc = func(d time.Duration, f func()) func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		t := &fakeTimer{at: now.Add(d), f: f}
+		timers = append(timers, t)
+		return func() bool {
+			mutex.Lock()
+			defer mutex.Unlock()
+			stopped := !t.ended
+			t.ended = true
+			return stopped
+		}
+	}
+	advance = func(d time.Duration) {
+		mutex.Lock()
+		now = now.Add(d)
+		due := []*fakeTimer{}
+		for _, t := range timers {
+			if !t.ended && !t.at.After(now) {
+				t.ended = true
+				due = append(due, t)
+			}
+		}
+		mutex.Unlock()
+		sort.Slice(due, func(i, j int) bool { return due[i].at.Before(due[j].at) })
+		for _, t := range due {
+			t.f()
+		}
+	}
+	restore = func() {
+		timeNow = time.Now
+		timeAfterFunc = func(d time.Duration, f func()) func() bool {
+			return time.AfterFunc(d, f).Stop
+		}
+	}
+	return advance, restore
+}
+
+// FakeSamplingTicker makes sampling writers made after the call end their intervals only
+// when tick is called, which returns after the summaries were written
+// restore puts back the real ticker
+func FakeSamplingTicker() (tick func(), restore func()) {
+	type fakeTicker struct {
+		f       func()
+		stopped bool
+	}
+	var (
+		mutex   sync.Mutex
+		tickers []*fakeTicker
+	)
+	realTicker := sampleTicker
+	sampleTicker = func(d time.Duration, f func()) func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		t := &fakeTicker{f: f}
+		tickers = append(tickers, t)
+		return func() {
+			mutex.Lock()
+			defer mutex.Unlock()
+			t.stopped = true
+		}
+	}
+	tick = func() {
+		mutex.Lock()
+		due := []*fakeTicker{}
+		for _, t := range tickers {
+			if !t.stopped {
+				due = append(due, t)
+			}
+		}
+		mutex.Unlock()
+		for _, t := range due {
+			t.f()
+		}
+	}
+	restore = func() {
+		sampleTicker = realTicker
+	}
+	return tick, restore
+}
@@ -0,0 +1,200 @@
+package logger
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Field is a named value added to a record with WithFields() or the fields of a log method, e.g.
+//
+//	l.Info("done", logger.String("user", name), logger.Int("count", n))
+//
+// unlike With(), fields are kept in a slice that is only appended to, and values of the
+// typed constructors are not boxed in an interface{}, so encoders write them without reflection
+// fields are written after the data, and durations are written as text, e.g. "1.5s"
+// the last value of a name wins: a field replaces data and earlier fields with the same name,
+// e.g. a field of the log call replaces one of WithFields(), and writers write the name once
+type Field struct {
+	name string
+	kind fieldKind
+	num  int64       //int, bool, duration, float bits or unix nanoseconds of a time
+	str  string      //string
+	any  interface{} //error, *time.Location of a time, or the value of Any()
+}
+
+type fieldKind uint8
+
+const (
+	fieldAny fieldKind = iota
+	fieldString
+	fieldInt64
+	fieldFloat64
+	fieldBool
+	fieldDuration
+	fieldTime
+	fieldError
+)
+
+func String(name string, value string) Field {
+	return Field{name: name, kind: fieldString, str: value}
+}
+
+func Int(name string, value int) Field {
+	return Field{name: name, kind: fieldInt64, num: int64(value)}
+}
+
+func Int64(name string, value int64) Field {
+	return Field{name: name, kind: fieldInt64, num: value}
+}
+
+func Float64(name string, value float64) Field {
+	return Field{name: name, kind: fieldFloat64, num: int64(math.Float64bits(value))}
+}
+
+func Bool(name string, value bool) Field {
+	f := Field{name: name, kind: fieldBool}
+	if value {
+		f.num = 1
+	}
+	return f
+}
+
+func Duration(name string, value time.Duration) Field {
+	return Field{name: name, kind: fieldDuration, num: int64(value)}
+}
+
+// Time keeps the time as unix nanoseconds and location when it fits, else as interface{}
+func Time(name string, value time.Time) Field {
+	if value.Year() < 1678 || value.Year() > 2261 {
+		return Field{name: name, kind: fieldAny, any: value}
+	}
+	return Field{name: name, kind: fieldTime, num: value.UnixNano(), any: value.Location()}
+}
+
+// Err makes a field named "error", use NamedErr() for another name
+// rather use WithError() or Errorw() for the main error of a record
+func Err(err error) Field {
+	return NamedErr("error", err)
+}
+
+func NamedErr(name string, err error) Field {
+	if err == nil {
+		return Field{name: name, kind: fieldAny}
+	}
+	return Field{name: name, kind: fieldError, any: err}
+}
+
+// Any makes a typed field for the types that have a constructor, else keeps the value as interface{}
+func Any(name string, value interface{}) Field {
+	switch v := value.(type) {
+	case string:
+		return String(name, v)
+	case int:
+		return Int(name, v)
+	case int64:
+		return Int64(name, v)
+	case float64:
+		return Float64(name, v)
+	case bool:
+		return Bool(name, v)
+	case time.Duration:
+		return Duration(name, v)
+	case time.Time:
+		return Time(name, v)
+	case error:
+		return NamedErr(name, v)
+	}
+	return Field{name: name, kind: fieldAny, any: value}
+}
+
+func (f Field) Name() string { return f.name }
+
+// Value returns the value as interface{}, like it would be stored with With()
+func (f Field) Value() interface{} {
+	switch f.kind {
+	case fieldString:
+		return f.str
+	case fieldInt64:
+		return f.num
+	case fieldFloat64:
+		return math.Float64frombits(uint64(f.num))
+	case fieldBool:
+		return f.num == 1
+	case fieldDuration:
+		return time.Duration(f.num)
+	case fieldTime:
+		return f.time()
+	}
+	return f.any
+}
+
+func (f Field) time() time.Time {
+	t := time.Unix(0, f.num)
+	if loc, ok := f.any.(*time.Location); ok {
+		t = t.In(loc)
+	}
+	return t
+}
+
+// String returns name=value with the value quoted when needed, like the logfmt writer writes it
+func (f Field) String() string {
+	buf := bytes.Buffer{}
+	appendLogfmtKey(&buf, f.name)
+	buf.WriteByte('=')
+	appendLogfmtValue(&buf, f.text(time.RFC3339Nano))
+	return buf.String()
+}
+
+// text returns the value as text, formatting times with the layout
+func (f Field) text(timeFormat string) string {
+	switch f.kind {
+	case fieldString:
+		return f.str
+	case fieldInt64:
+		return strconv.FormatInt(f.num, 10)
+	case fieldFloat64:
+		return strconv.FormatFloat(math.Float64frombits(uint64(f.num)), 'g', -1, 64)
+	case fieldBool:
+		return strconv.FormatBool(f.num == 1)
+	case fieldDuration:
+		return time.Duration(f.num).String()
+	case fieldTime:
+		return f.time().Format(timeFormat)
+	case fieldError:
+		return f.any.(error).Error()
+	}
+	if t, ok := f.any.(time.Time); ok {
+		return t.Format(timeFormat)
+	}
+	return dataValueString(f.any)
+}
+
+// appendJSON writes the value as JSON, or returns false for a value of Any() that must be marshalled
+func (f Field) appendJSON(buf *bytes.Buffer, timeFormat string) bool {
+	switch f.kind {
+	case fieldString:
+		appendJSONString(buf, f.str)
+	case fieldInt64:
+		buf.WriteString(strconv.FormatInt(f.num, 10))
+	case fieldFloat64:
+		v := math.Float64frombits(uint64(f.num))
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			appendJSONString(buf, strconv.FormatFloat(v, 'g', -1, 64))
+		} else {
+			buf.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+		}
+	case fieldBool:
+		buf.WriteString(strconv.FormatBool(f.num == 1))
+	case fieldDuration:
+		appendJSONString(buf, time.Duration(f.num).String())
+	case fieldTime:
+		appendJSONString(buf, f.time().Format(timeFormat))
+	case fieldError:
+		appendJSONString(buf, f.any.(error).Error())
+	default:
+		return false
+	}
+	return true
+}
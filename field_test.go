@@ -0,0 +1,182 @@
+package logger_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-msvc/logger"
+)
+
+func TestFieldValues(t *testing.T) {
+	at := time.Date(2024, 5, 6, 7, 8, 9, 10, time.UTC)
+	err := fmt.Errorf("failed")
+	tests := []struct {
+		field logger.Field
+		value interface{}
+		text  string
+	}{
+		{logger.String("s", "a b"), "a b", `s="a b"`},
+		{logger.Int("i", -3), int64(-3), "i=-3"},
+		{logger.Int64("i64", 1<<40), int64(1 << 40), "i64=1099511627776"},
+		{logger.Float64("f", 1.5), 1.5, "f=1.5"},
+		{logger.Bool("b", true), true, "b=true"},
+		{logger.Duration("d", 1500*time.Millisecond), 1500 * time.Millisecond, "d=1.5s"},
+		{logger.Time("t", at), at, "t=2024-05-06T07:08:09.00000001Z"},
+		{logger.Err(err), err, "error=failed"},
+		{logger.Any("a", []int{1, 2}), nil, `a="[1 2]"`},
+		{logger.Any("n", nil), nil, "n=null"},
+	}
+	for _, test := range tests {
+		if test.value != nil && test.field.Value() != test.value {
+			t.Errorf("%s: value %#v != %#v", test.field.Name(), test.field.Value(), test.value)
+		}
+		if s := test.field.String(); s != test.text {
+			t.Errorf("%s: text %q != %q", test.field.Name(), s, test.text)
+		}
+	}
+	if !logger.Time("t", at).Value().(time.Time).Equal(at) {
+		t.Fatalf("time not equal")
+	}
+}
+
+func TestWithFields(t *testing.T) {
+	w := &testWriter{records: []logger.Record{}}
+	l := logger.Named("example.com/fields")
+	l.SetWriter(w)
+	defer l.SetWriter(nil)
+
+	parent := l.With("user", "joe").WithFields(logger.Int("a", 1))
+	one := parent.WithFields(logger.String("b", "x"))
+	two := parent.WithFields(logger.String("b", "y"))
+	one.Error("one", logger.Bool("c", true))
+	two.Error("two")
+	parent.Error("three")
+	if len(w.records) != 3 {
+		t.Fatalf("records: %+v", w.records)
+	}
+	names := func(fields []logger.Field) string {
+		s := []string{}
+		for _, f := range fields {
+			s = append(s, f.String())
+		}
+		return strings.Join(s, " ")
+	}
+	for i, expected := range []string{"a=1 b=x c=true", "a=1 b=y", "a=1"} {
+		if s := names(w.records[i].Fields); s != expected {
+			t.Errorf("record %d fields %q != %q", i, s, expected)
+		}
+	}
+	w.assert(t, 0, "TestWithFields", l.Name(), "ERROR", "one", map[string]interface{}{"user": "joe"})
+
+	r := w.records[0]
+	r.Caller = nil
+	r.Logger = nil
+	r.Fields = append(r.Fields, logger.String("msg", "clash"), logger.Any("list", []int{1}))
+	encode := func(e logger.IEncoder) string {
+		buf := bytes.NewBuffer(nil)
+		e.Encode(buf, r)
+		return strings.TrimSuffix(buf.String(), "\n")
+	}
+	if s := encode(logger.NewJSONEncoder(logger.JSONConfig{TimeKey: "-", LevelKey: "-", NameKey: "-"})); s != `{"msg":"one","user":"joe","a":1,"b":"x","c":true,"data.msg":"clash","list":[1]}` {
+		t.Fatalf("json: %s", s)
+	}
+	if s := encode(logger.NewLogfmtEncoder(logger.LogfmtConfig{})); !strings.HasSuffix(s, ` msg=one user=joe a=1 b=x c=true data.msg=clash list=[1]`) {
+		t.Fatalf("logfmt: %s", s)
+	}
+	text, _ := logger.NewTextEncoder(`{{.Message}} {{.Field "b"}}{{range .Fields}} {{.}}{{end}}`)
+	if s := encode(text); s != "one x a=1 b=x c=true msg=clash list=[1]" {
+		t.Fatalf("text: %s", s)
+	}
+}
+
+func TestFieldClash(t *testing.T) {
+	r := logger.Record{
+		Message: "hi",
+		Data:    map[string]interface{}{"user": "joe", "n": 1},
+		Keys:    []string{"user", "n"},
+		Fields:  []logger.Field{logger.String("user", "a"), logger.Int("c", 1), logger.String("user", "b")},
+	}
+	encode := func(e logger.IEncoder) string {
+		buf := bytes.NewBuffer(nil)
+		e.Encode(buf, r)
+		return strings.TrimSuffix(buf.String(), "\n")
+	}
+	//the last value of a name wins and the name is written once
+	if s := encode(logger.NewJSONEncoder(logger.JSONConfig{TimeKey: "-", LevelKey: "-", NameKey: "-"})); s != `{"msg":"hi","n":1,"c":1,"user":"b"}` {
+		t.Fatalf("json: %s", s)
+	}
+	if s := encode(logger.NewLogfmtEncoder(logger.LogfmtConfig{})); !strings.HasSuffix(s, ` msg=hi n=1 c=1 user=b`) {
+		t.Fatalf("logfmt: %s", s)
+	}
+	text, _ := logger.NewTextEncoder(`{{.Data}}{{range .Fields}} {{.}}{{end}} {{.Field "user"}}`)
+	if s := encode(text); s != "map[n:1] c=1 user=b b" {
+		t.Fatalf("text: %s", s)
+	}
+	if !logger.DataFilter("c")(r) || logger.DataFilter("x")(r) {
+		t.Fatalf("data filter does not see fields")
+	}
+}
+
+// benchmarks compare adding values with With() to adding the same values as fields
+
+func benchmarkLogger(b *testing.B, name string) logger.Logger {
+	l := logger.Named(name)
+	l.SetLevel(logger.LevelInfo)
+	l.SetWriter(writerFunc(func(logger.Record) {}))
+	b.Cleanup(func() {
+		l.SetWriter(nil)
+		l.SetLevel(logger.LevelDefault)
+	})
+	b.ReportAllocs()
+	return l
+}
+
+func BenchmarkWith(b *testing.B) {
+	l := benchmarkLogger(b, "example.com/bench/with")
+	for i := 0; i < b.N; i++ {
+		l.With("user", "joe").With("count", i).With("ok", true).Info("done")
+	}
+}
+
+func BenchmarkWithFields(b *testing.B) {
+	l := benchmarkLogger(b, "example.com/bench/withfields")
+	for i := 0; i < b.N; i++ {
+		l.WithFields(logger.String("user", "joe"), logger.Int("count", i), logger.Bool("ok", true)).Info("done")
+	}
+}
+
+func BenchmarkFields(b *testing.B) {
+	l := benchmarkLogger(b, "example.com/bench/fields")
+	for i := 0; i < b.N; i++ {
+		l.Info("done", logger.String("user", "joe"), logger.Int("count", i), logger.Bool("ok", true))
+	}
+}
+
+func BenchmarkWithJSON(b *testing.B) {
+	l := benchmarkLogger(b, "example.com/bench/withjson")
+	e := logger.NewJSONEncoder(logger.JSONConfig{})
+	buf := bytes.NewBuffer(nil)
+	l.SetWriter(writerFunc(func(r logger.Record) {
+		buf.Reset()
+		e.Encode(buf, r)
+	}))
+	for i := 0; i < b.N; i++ {
+		l.With("user", "joe").With("count", i).With("ok", true).Info("done")
+	}
+}
+
+func BenchmarkFieldsJSON(b *testing.B) {
+	l := benchmarkLogger(b, "example.com/bench/fieldsjson")
+	e := logger.NewJSONEncoder(logger.JSONConfig{})
+	buf := bytes.NewBuffer(nil)
+	l.SetWriter(writerFunc(func(r logger.Record) {
+		buf.Reset()
+		e.Encode(buf, r)
+	}))
+	for i := 0; i < b.N; i++ {
+		l.Info("done", logger.String("user", "joe"), logger.Int("count", i), logger.Bool("ok", true))
+	}
+}
@@ -0,0 +1,383 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// IFileWriter writes records to a file that is rotated
+type IFileWriter interface {
+	IWriter
+	IFlusher
+	ICloser
+	Rotate() error //renames the current file to a backup and starts a new file
+	Reopen() error //closes and opens the file with the same name, e.g. after logrotate moved it
+}
+
+// FileConfig controls NewFileWriter()
+type FileConfig struct {
+	Filename string   //e.g. "/var/log/app/app.log"
+	Encoder  IEncoder //default is text with DefaultTextLayout
+
+	MaxSize int64          //rotate before the file grows bigger than this many bytes, 0 for no limit
+	Rotate  RotateInterval //also rotate at the start of each day or hour
+
+	MaxBackups int           //keep at most this many rotated files, 0 to keep all
+	MaxAge     time.Duration //delete rotated files older than this, 0 to keep all
+	Compress   bool          //gzip rotated files in the background
+
+	ReopenOnSIGHUP bool //reopen the file when the process gets SIGHUP, for use with logrotate
+}
+
+// RotateInterval says when a file writer starts a new file regardless of its size
+type RotateInterval int
+
+const (
+	RotateNever RotateInterval = iota
+	RotateDaily
+	RotateHourly
+)
+
+// backupTimeFormat is added to the name of rotated files, e.g. app-2021-01-02T15-04-05.000.log
+// the time is in UTC, so that the names sort in the order of rotation also when daylight saving ends
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// NewFileWriter opens the file for appending and returns a writer that is safe to use
+// from many goroutines. Rotated files are named with the time of rotation.
+func NewFileWriter(c FileConfig) (IFileWriter, error) {
+	if c.Filename == "" {
+		return nil, fmt.Errorf("missing filename")
+	}
+	if c.Encoder == nil {
+		c.Encoder, _ = NewTextEncoder(DefaultTextLayout)
+	}
+	fw := &fileWriter{
+		config:   c,
+		cleanup:  make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+	if err := fw.open(); err != nil {
+		return nil, err
+	}
+
+	var hup chan os.Signal
+	if c.ReopenOnSIGHUP {
+		hup = make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+	}
+	go fw.background(hup)
+	fw.triggerCleanup()
+	return fw, nil
+} //NewFileWriter()
+
+type fileWriter struct {
+	config FileConfig
+
+	sync.Mutex
+	file       *os.File
+	size       int64
+	nextRotate time.Time //zero when not rotating by time
+	buf        bytes.Buffer
+	lastErr    string //last error written to stderr, to not repeat it for every record
+
+	cleanup  chan struct{} //signals background() to compress and delete backups
+	stop     chan struct{}
+	finished chan struct{}
+	closed   bool
+}
+
+// open opens the file and works out when to rotate it
+// must be called with fw locked, or before fw is used
+func (fw *fileWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(fw.config.Filename), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(fw.config.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	fw.file = f
+	fw.size = info.Size()
+
+	now := time.Now()
+	switch fw.config.Rotate {
+	case RotateDaily:
+		y, m, d := now.Date()
+		fw.nextRotate = time.Date(y, m, d+1, 0, 0, 0, 0, now.Location())
+	case RotateHourly:
+		fw.nextRotate = now.Truncate(time.Hour).Add(time.Hour)
+	default:
+		fw.nextRotate = time.Time{}
+	}
+	return nil
+} //fileWriter.open()
+
+func (fw *fileWriter) Write(r Record) {
+	fw.Lock()
+	defer fw.Unlock()
+	if fw.closed {
+		return
+	}
+	fw.buf.Reset()
+	fw.config.Encoder.Encode(&fw.buf, r)
+
+	if (!fw.nextRotate.IsZero() && !time.Now().Before(fw.nextRotate)) ||
+		(fw.config.MaxSize > 0 && fw.size > 0 && fw.size+int64(fw.buf.Len()) > fw.config.MaxSize) {
+		if err := fw.rotate(); err != nil {
+			fw.report(err)
+		}
+	}
+	if fw.file == nil {
+		return //could not reopen after rotate
+	}
+	n, err := fw.file.Write(fw.buf.Bytes())
+	fw.size += int64(n)
+	if err != nil {
+		fw.report(err)
+	}
+} //fileWriter.Write()
+
+// report writes an error to stderr, because a writer cannot return errors
+func (fw *fileWriter) report(err error) {
+	if err.Error() != fw.lastErr {
+		fw.lastErr = err.Error()
+		fmt.Fprintf(os.Stderr, "logger: file writer %s: %v\n", fw.config.Filename, err)
+	}
+}
+
+func (fw *fileWriter) Rotate() error {
+	fw.Lock()
+	defer fw.Unlock()
+	if fw.closed {
+		return fmt.Errorf("file writer closed")
+	}
+	return fw.rotate()
+}
+
+// rotate renames the file to a backup and opens a new file
+// must be called with fw locked
+func (fw *fileWriter) rotate() error {
+	if fw.file != nil {
+		fw.file.Close()
+		fw.file = nil
+	}
+	ext := filepath.Ext(fw.config.Filename)
+	var backup string
+	for t := time.Now().UTC(); ; t = t.Add(time.Millisecond) {
+		//next millisecond if rotated more than once in the same millisecond
+		backup = strings.TrimSuffix(fw.config.Filename, ext) + "-" + t.Format(backupTimeFormat) + ext
+		if _, err := os.Stat(backup); os.IsNotExist(err) {
+			if _, err := os.Stat(backup + ".gz"); os.IsNotExist(err) {
+				break
+			}
+		}
+	}
+	if err := os.Rename(fw.config.Filename, backup); err != nil && !os.IsNotExist(err) {
+		fw.open() //keep writing to the same file
+		return err
+	}
+	if err := fw.open(); err != nil {
+		return err
+	}
+	fw.triggerCleanup()
+	return nil
+}
+
+func (fw *fileWriter) Reopen() error {
+	fw.Lock()
+	defer fw.Unlock()
+	if fw.closed {
+		return fmt.Errorf("file writer closed")
+	}
+	if fw.file != nil {
+		fw.file.Close()
+		fw.file = nil
+	}
+	return fw.open()
+}
+
+func (fw *fileWriter) Flush(ctx context.Context) error {
+	fw.Lock()
+	defer fw.Unlock()
+	if fw.file == nil {
+		return nil
+	}
+	return fw.file.Sync()
+}
+
+// Close closes the file and waits for background compression to finish
+func (fw *fileWriter) Close() error {
+	fw.Lock()
+	if fw.closed {
+		fw.Unlock()
+		return nil
+	}
+	fw.closed = true
+	var err error
+	if fw.file != nil {
+		err = fw.file.Close()
+		fw.file = nil
+	}
+	fw.Unlock()
+
+	close(fw.stop)
+	<-fw.finished
+	return err
+}
+
+func (fw *fileWriter) triggerCleanup() {
+	if fw.config.Compress || fw.config.MaxBackups > 0 || fw.config.MaxAge > 0 {
+		select {
+		case fw.cleanup <- struct{}{}:
+		default: //already triggered
+		}
+	}
+}
+
+// background compresses and deletes backups and reopens the file on SIGHUP
+func (fw *fileWriter) background(hup chan os.Signal) {
+	defer close(fw.finished)
+	if hup != nil {
+		defer signal.Stop(hup)
+	}
+	for {
+		select {
+		case <-fw.stop:
+			//finish a cleanup triggered by the last rotation
+			select {
+			case <-fw.cleanup:
+				fw.runCleanup()
+			default:
+			}
+			return
+		case <-fw.cleanup:
+			fw.runCleanup()
+		case <-hup:
+			if err := fw.Reopen(); err != nil {
+				fw.Lock()
+				fw.report(err)
+				fw.Unlock()
+			}
+		}
+	}
+}
+
+func (fw *fileWriter) runCleanup() {
+	if err := fw.cleanupBackups(); err != nil {
+		fw.Lock()
+		fw.report(err)
+		fw.Unlock()
+	}
+}
+
+// backups returns the names of rotated files, oldest first
+func (fw *fileWriter) backups() ([]string, error) {
+	//list the directory rather than use filepath.Glob(), which would treat *, ? and [ in the filename as patterns
+	dir, base := filepath.Split(fw.config.Filename)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return nil, err
+	}
+	backups := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".gz"), ext)
+		if _, err := time.Parse(backupTimeFormat, ts); err == nil {
+			backups = append(backups, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+func (fw *fileWriter) cleanupBackups() error {
+	backups, err := fw.backups()
+	if err != nil {
+		return err
+	}
+
+	keep := []string{}
+	for i, name := range backups {
+		remove := fw.config.MaxBackups > 0 && i < len(backups)-fw.config.MaxBackups
+		if !remove && fw.config.MaxAge > 0 {
+			if info, err := os.Stat(name); err == nil && time.Since(info.ModTime()) > fw.config.MaxAge {
+				remove = true
+			}
+		}
+		if remove {
+			if err := os.Remove(name); err != nil {
+				return err
+			}
+		} else {
+			keep = append(keep, name)
+		}
+	}
+
+	if fw.config.Compress {
+		for _, name := range keep {
+			if !strings.HasSuffix(name, ".gz") {
+				if err := gzipFile(name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+} //fileWriter.cleanupBackups()
+
+// gzipFile replaces the file with name.gz
+func gzipFile(name string) error {
+	in, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		out.Close()
+		os.Remove(name + ".gz")
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		os.Remove(name + ".gz")
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(name + ".gz")
+		return err
+	}
+	os.Chtimes(name+".gz", info.ModTime(), info.ModTime())
+	in.Close()
+	return os.Remove(name)
+} //gzipFile()
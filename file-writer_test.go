@@ -0,0 +1,175 @@
+package logger_test
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-msvc/logger"
+)
+
+func TestFileWriterRotate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "app.log")
+
+	encoder, _ := logger.NewTextEncoder("{{.Message}}")
+	fw, err := logger.NewFileWriter(logger.FileConfig{
+		Filename:   fileName,
+		Encoder:    encoder,
+		MaxSize:    20, //2 lines of 10 bytes
+		MaxBackups: 2,
+		Compress:   true,
+	})
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	wg := sync.WaitGroup{}
+	for g := 0; g < 2; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 4; i++ {
+				fw.Write(logger.Record{Message: fmt.Sprintf("line %d.%02d", g, i)})
+			}
+		}(g)
+	}
+	wg.Wait()
+	fw.Write(logger.Record{Message: "line last"})
+	if err := fw.Flush(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if err := fw.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	//9 lines of 10 bytes: 4 full files rotated, only the last 2 kept and compressed
+	names, _ := filepath.Glob(filepath.Join(dir, "*"))
+	sort.Strings(names)
+	if len(names) != 3 || names[2] != fileName {
+		t.Fatalf("files: %v", names)
+	}
+	for _, name := range names[:2] {
+		if !strings.HasPrefix(filepath.Base(name), "app-") || !strings.HasSuffix(name, ".log.gz") {
+			t.Fatalf("wrong backup name: %s", name)
+		}
+		//the time in the name is in UTC
+		ts, err := time.Parse("2006-01-02T15-04-05.000", strings.TrimSuffix(strings.TrimPrefix(filepath.Base(name), "app-"), ".log.gz"))
+		if err != nil || time.Since(ts) < -time.Second || time.Since(ts) > time.Minute {
+			t.Fatalf("backup time not UTC: %s", name)
+		}
+		f, _ := os.Open(name)
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("not gzip: %s: %v", name, err)
+		}
+		data, _ := ioutil.ReadAll(zr)
+		f.Close()
+		if len(data) != 20 {
+			t.Fatalf("%s: %q", name, data)
+		}
+	}
+	if data, _ := ioutil.ReadFile(fileName); string(data) != "line last\n" {
+		t.Fatalf("current file: %q", data)
+	}
+}
+
+func TestFileWriterReopenAndAge(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "app.log")
+
+	//old backup is removed
+	old := filepath.Join(dir, "app-2000-01-01T00-00-00.000.log")
+	ioutil.WriteFile(old, []byte("old\n"), 0644)
+	os.Chtimes(old, time.Now().Add(-48*time.Hour), time.Now().Add(-48*time.Hour))
+	other := filepath.Join(dir, "app-other.log")
+	ioutil.WriteFile(other, []byte("other\n"), 0644)
+	os.Chtimes(other, time.Now().Add(-48*time.Hour), time.Now().Add(-48*time.Hour))
+
+	encoder, _ := logger.NewTextEncoder("{{.Message}}")
+	fw, err := logger.NewFileWriter(logger.FileConfig{
+		Filename: fileName,
+		Encoder:  encoder,
+		MaxAge:   24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	fw.Write(logger.Record{Message: "one"})
+
+	//logrotate moves the file away then signals to reopen
+	os.Rename(fileName, fileName+".1")
+	if err := fw.Reopen(); err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	fw.Write(logger.Record{Message: "two"})
+	fw.Close()
+	fw.Write(logger.Record{Message: "three"})
+
+	if data, _ := ioutil.ReadFile(fileName + ".1"); string(data) != "one\n" {
+		t.Fatalf("moved file: %q", data)
+	}
+	if data, _ := ioutil.ReadFile(fileName); string(data) != "two\n" {
+		t.Fatalf("reopened file: %q", data)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Fatalf("old backup not removed")
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Fatalf("file that is not a backup was removed")
+	}
+}
+
+func TestFileWriterPatternName(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "app[1]*.log")
+	other := filepath.Join(dir, "app1-2006-01-02T15-04-05.000.log") //matches the glob app[1]*-*
+	if err := os.WriteFile(other, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	encoder, _ := logger.NewTextEncoder("{{.Message}}")
+	fw, err := logger.NewFileWriter(logger.FileConfig{
+		Filename:   fileName,
+		Encoder:    encoder,
+		MaxBackups: 1,
+	})
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		fw.Write(logger.Record{Message: fmt.Sprintf("%d", i)})
+		if err := fw.Rotate(); err != nil {
+			t.Fatalf("rotate: %v", err)
+		}
+	}
+	if err := fw.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	//only the last backup of this file is kept, and the other file is not touched
+	entries, _ := os.ReadDir(dir)
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	if len(names) != 3 || names[0] != "app1-2006-01-02T15-04-05.000.log" || !strings.HasPrefix(names[1], "app[1]*-") || names[2] != "app[1]*.log" {
+		t.Fatalf("files: %v", names)
+	}
+}
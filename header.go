@@ -1,4 +1,137 @@
 package logger
 
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Header is request and trace metadata written with a record
+// writers write it as fields of their own, separate from Record.Data
+// the service, version, host and pid of the process are in Record.Resource, see SetResource()
 type Header struct {
+	TraceID   string `json:"trace_id,omitempty"`
+	SpanID    string `json:"span_id,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	Tenant    string `json:"tenant,omitempty"`
+	User      string `json:"user,omitempty"`
+}
+
+// IsZero is true when no header fields are set
+func (h Header) IsZero() bool {
+	return h == Header{}
+}
+
+// Merge returns h with the fields that are set in other
+func (h Header) Merge(other Header) Header {
+	set := func(s *string, v string) {
+		if v != "" {
+			*s = v
+		}
+	}
+	set(&h.TraceID, other.TraceID)
+	set(&h.SpanID, other.SpanID)
+	set(&h.RequestID, other.RequestID)
+	set(&h.Tenant, other.Tenant)
+	set(&h.User, other.User)
+	return h
+}
+
+// headerField is one header field that is set, named like its json tag
+type headerField struct {
+	name  string
+	value string
+}
+
+// fields lists the fields that are set, in the order they are declared
+func (h Header) fields() []headerField {
+	if h.IsZero() {
+		return nil
+	}
+	list := make([]headerField, 0, 5)
+	add := func(name, value string) {
+		if value != "" {
+			list = append(list, headerField{name: name, value: value})
+		}
+	}
+	add("trace_id", h.TraceID)
+	add("span_id", h.SpanID)
+	add("request_id", h.RequestID)
+	add("tenant", h.Tenant)
+	add("user", h.User)
+	return list
+}
+
+// hasHeaderField is true when the list has a field with the name
+func hasHeaderField(list []headerField, name string) bool {
+	for _, f := range list {
+		if f.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the fields that are set as key=value, e.g. "trace_id=4bf9... request_id=r1"
+func (h Header) String() string {
+	buf := bytes.Buffer{}
+	for i, f := range h.fields() {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(f.name)
+		buf.WriteByte('=')
+		appendLogfmtValue(&buf, f.value)
+	}
+	return buf.String()
+}
+
+// ParseTraceparent reads the trace and span ids from a W3C traceparent header value
+// e.g. "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+func ParseTraceparent(s string) (Header, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return Header{}, fmt.Errorf("invalid traceparent %q", s)
+	}
+	if !isLowerHex(parts[0]) || !isLowerHex(parts[3]) || len(parts[3]) != 2 {
+		return Header{}, fmt.Errorf("invalid traceparent %q", s)
+	}
+	traceID, spanID := parts[1], parts[2]
+	if len(traceID) != 32 || !isLowerHex(traceID) || traceID == strings.Repeat("0", 32) {
+		return Header{}, fmt.Errorf("invalid traceparent trace-id %q", traceID)
+	}
+	if len(spanID) != 16 || !isLowerHex(spanID) || spanID == strings.Repeat("0", 16) {
+		return Header{}, fmt.Errorf("invalid traceparent parent-id %q", spanID)
+	}
+	return Header{TraceID: traceID, SpanID: spanID}, nil
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !(s[i] >= '0' && s[i] <= '9' || s[i] >= 'a' && s[i] <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+type headerKey struct{}
+
+// ContextWithHeader returns a copy of ctx with h merged into the header already in ctx
+// the XxxCtx() log methods and Ctx() add it to the logger's header
+func ContextWithHeader(ctx context.Context, h Header) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, headerKey{}, HeaderFromContext(ctx).Merge(h))
+}
+
+// HeaderFromContext returns the header stored with ContextWithHeader(), or an empty header
+func HeaderFromContext(ctx context.Context) Header {
+	if ctx == nil {
+		return Header{}
+	}
+	h, _ := ctx.Value(headerKey{}).(Header)
+	return h
 }
@@ -0,0 +1,101 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-msvc/logger"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	h, err := logger.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil || h.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || h.SpanID != "00f067aa0ba902b7" {
+		t.Fatalf("%+v %v", h, err)
+	}
+	for _, s := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		if _, err := logger.ParseTraceparent(s); err == nil {
+			t.Fatalf("parsed invalid %q", s)
+		}
+	}
+	//later versions may add fields
+	if _, err := logger.ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"); err != nil {
+		t.Fatalf("version 01: %v", err)
+	}
+}
+
+func TestHeader(t *testing.T) {
+	w := &testWriter{records: []logger.Record{}}
+	l := logger.Named("example.com/header").WithHeader(logger.Header{User: "u0", Tenant: "t1"})
+	l.SetWriter(w)
+	defer l.SetWriter(nil)
+
+	h, _ := logger.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := logger.ContextWithHeader(context.Background(), h)
+	ctx = logger.ContextWithHeader(ctx, logger.Header{RequestID: "r1", Tenant: "t2"})
+
+	l.With("a", 1).Error("plain")
+	l.ErrorCtx(ctx, "ctx")
+	l.Ctx(ctx).WithHeader(logger.Header{User: "joe"}).Error("with")
+
+	expected := []logger.Header{
+		{User: "u0", Tenant: "t1"},
+		{User: "u0", Tenant: "t2", TraceID: h.TraceID, SpanID: h.SpanID, RequestID: "r1"},
+		{User: "joe", Tenant: "t2", TraceID: h.TraceID, SpanID: h.SpanID, RequestID: "r1"},
+	}
+	if len(w.records) != len(expected) {
+		t.Fatalf("%d records", len(w.records))
+	}
+	for i, e := range expected {
+		if w.records[i].Header != e {
+			t.Fatalf("record %d: %+v != %+v", i, w.records[i].Header, e)
+		}
+	}
+}
+
+func TestHeaderEncoders(t *testing.T) {
+	r := logger.Record{
+		Timestamp: time.Date(2021, 1, 2, 15, 4, 5, 0, time.UTC),
+		Level:     logger.LevelInfo,
+		Message:   "hi",
+		Header:    logger.Header{TraceID: "abc", RequestID: "r1", User: "joe"},
+		Data:      map[string]interface{}{"user": "data user", "n": 1},
+		Keys:      []string{"user", "n"},
+	}
+	encode := func(e logger.IEncoder) string {
+		buf := bytes.NewBuffer(nil)
+		e.Encode(buf, r)
+		return strings.TrimSuffix(buf.String(), "\n")
+	}
+	if s := encode(logger.NewJSONEncoder(logger.JSONConfig{TimeKey: "-"})); s != `{"level":"INFO","logger":"","msg":"hi","trace_id":"abc","request_id":"r1","user":"joe","n":1,"data.user":"data user"}` {
+		t.Fatalf("json: %s", s)
+	}
+	if s := encode(logger.NewJSONEncoder(logger.JSONConfig{TimeKey: "-", HeaderKey: "header"})); s != `{"level":"INFO","logger":"","msg":"hi","header":{"trace_id":"abc","request_id":"r1","user":"joe"},"n":1,"user":"data user"}` {
+		t.Fatalf("json with header key: %s", s)
+	}
+	//data named like the header object is written as data.<name>
+	r.Data["hdr"] = 1
+	r.Keys = append(r.Keys, "hdr")
+	if s := encode(logger.NewJSONEncoder(logger.JSONConfig{TimeKey: "-", LevelKey: "-", NameKey: "-", HeaderKey: "hdr"})); s != `{"msg":"hi","hdr":{"trace_id":"abc","request_id":"r1","user":"joe"},"data.hdr":1,"n":1,"user":"data user"}` {
+		t.Fatalf("json with data named like the header key: %s", s)
+	}
+	delete(r.Data, "hdr")
+	r.Keys = r.Keys[:2]
+	if s := encode(logger.NewLogfmtEncoder(logger.LogfmtConfig{})); s != `time=2021-01-02T15:04:05Z level=INFO logger="" msg=hi trace_id=abc request_id=r1 user=joe data.user="data user" n=1` {
+		t.Fatalf("logfmt: %s", s)
+	}
+	text, _ := logger.NewTextEncoder(logger.DefaultTextLayout)
+	if s := encode(text); !strings.HasSuffix(s, ": hi trace_id=abc request_id=r1 user=joe map[n:1 user:data user]") {
+		t.Fatalf("text: %s", s)
+	}
+}
@@ -0,0 +1,356 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// JSONConfig controls how NewJSONEncoder() writes records
+// empty values use the defaults, and a key set to "-" is left out of the output
+type JSONConfig struct {
+	TimeKey       string //default "time"
+	LevelKey      string //default "level"
+	NameKey       string //default "logger"
+	CallerKey     string //default "caller", written as object with package, file, line and function
+	StackKey      string //default "stack", written as array of objects like the caller when the record has a stack
+	ErrorStackKey string //default "error_stack", like StackKey for Record.ErrorStack
+	MessageKey    string //default "msg"
+	ErrorKey      string //default "error", written as object with message, type, chain and fields, see GetErrorInfo()
+
+	//DataKey is the name of an object that holds Record.Data
+	//when empty, data is written as top-level fields and a data name that clashes
+	//with one of the keys of this config, e.g. HeaderKey, is written as "data.<name>"
+	DataKey string
+
+	//HeaderKey is the name of an object that holds Record.Header
+	//when empty, header fields are written as top-level fields, e.g. "trace_id"
+	//and a data name that clashes with one of them is written as "data.<name>"
+	HeaderKey string
+
+	//ResourceKey is the name of an object that holds Record.Resource, which is left out when empty
+	ResourceKey string
+
+	TimeFormat string //default time.RFC3339Nano
+
+	OnMarshalError MarshalErrorPolicy //what to do with data values that cannot be marshalled
+}
+
+// MarshalErrorPolicy says what a writer does with a data value that cannot be marshalled
+type MarshalErrorPolicy int
+
+const (
+	MarshalErrorString MarshalErrorPolicy = iota //write the value as a string using fmt "%+v"
+	MarshalErrorOmit                             //leave the field out
+	MarshalErrorReport                           //write the marshal error text in place of the value
+)
+
+// NewJSONWriter makes a writer that writes one JSON object per line to w
+func NewJSONWriter(w io.Writer, c JSONConfig) IWriter {
+	return NewStreamWriter(w, NewJSONEncoder(c))
+}
+
+// NewJSONEncoder makes an encoder that writes one JSON object per record
+func NewJSONEncoder(c JSONConfig) IEncoder {
+	def := func(s *string, d string) {
+		if *s == "" {
+			*s = d
+		}
+	}
+	def(&c.TimeKey, "time")
+	def(&c.LevelKey, "level")
+	def(&c.NameKey, "logger")
+	def(&c.CallerKey, "caller")
+	def(&c.MessageKey, "msg")
+	def(&c.ErrorKey, "error")
+	def(&c.StackKey, "stack")
+	def(&c.ErrorStackKey, "error_stack")
+	def(&c.TimeFormat, time.RFC3339Nano)
+	return jsonEncoder{config: c}
+}
+
+type jsonEncoder struct {
+	config JSONConfig
+}
+
+func (e jsonEncoder) Encode(buf *bytes.Buffer, r Record) {
+	j := jsonObject{buf: buf}
+	j.open()
+	if e.config.TimeKey != "-" {
+		j.key(e.config.TimeKey)
+		appendJSONString(buf, r.Timestamp.Format(e.config.TimeFormat))
+	}
+	if e.config.LevelKey != "-" {
+		j.key(e.config.LevelKey)
+		appendJSONString(buf, r.Level.String())
+	}
+	if e.config.NameKey != "-" {
+		j.key(e.config.NameKey)
+		appendJSONString(buf, recordName(r))
+	}
+	if e.config.CallerKey != "-" && r.Caller != nil {
+		j.key(e.config.CallerKey)
+		appendJSONCaller(buf, r.Caller)
+	}
+	if e.config.MessageKey != "-" {
+		j.key(e.config.MessageKey)
+		appendJSONString(buf, r.Message)
+	}
+	if r.Error != nil && e.config.ErrorKey != "-" {
+		j.key(e.config.ErrorKey)
+		e.error(buf, GetErrorInfo(r.Error))
+	}
+	e.stack(&j, e.config.StackKey, r.Stack)
+	e.stack(&j, e.config.ErrorStackKey, r.ErrorStack)
+	header := r.Header.fields()
+	if len(header) > 0 && e.config.HeaderKey != "-" {
+		h := &j
+		if e.config.HeaderKey != "" {
+			j.key(e.config.HeaderKey)
+			h = &jsonObject{buf: buf}
+			h.open()
+		}
+		for _, f := range header {
+			h.key(f.name)
+			appendJSONString(buf, f.value)
+		}
+		if e.config.HeaderKey != "" {
+			h.close()
+		}
+	}
+	if res := r.Resource.fields(); len(res) > 0 && e.config.ResourceKey != "" && e.config.ResourceKey != "-" {
+		j.key(e.config.ResourceKey)
+		o := jsonObject{buf: buf}
+		o.open()
+		for _, f := range res {
+			o.key(f.name)
+			switch f.name {
+			case "pid", "vcs_modified":
+				buf.WriteString(f.value)
+			default:
+				appendJSONString(buf, f.value)
+			}
+		}
+		o.close()
+	}
+	if (len(r.Data) > 0 || len(r.Fields) > 0) && e.config.DataKey != "-" {
+		d := &j
+		if e.config.DataKey != "" {
+			j.key(e.config.DataKey)
+			d = &jsonObject{buf: buf}
+			d.open()
+		}
+		dataKey := func(n string) string {
+			if e.config.DataKey == "" && (e.reserved(n) || (e.config.HeaderKey == "" && hasHeaderField(header, n)) || (r.Error != nil && n == e.config.ErrorKey)) {
+				return "data." + n
+			}
+			return n
+		}
+		for _, n := range dataNames(r, true) {
+			e.value(d, dataKey(n), r.Data[n])
+		}
+		//fields are written in the order they were added, without reflection except for values of Any()
+		for _, f := range recordFields(r) {
+			key := dataKey(f.name)
+			if f.kind == fieldAny {
+				e.value(d, key, f.any)
+				continue
+			}
+			d.key(key)
+			f.appendJSON(buf, e.config.TimeFormat)
+		}
+		if e.config.DataKey != "" {
+			d.close()
+		}
+	}
+	j.close()
+	buf.WriteByte('\n')
+} //jsonEncoder.Encode()
+
+// error writes the error info as an object
+func (e jsonEncoder) error(buf *bytes.Buffer, info *ErrorInfo) {
+	o := jsonObject{buf: buf}
+	o.open()
+	o.key("message")
+	appendJSONString(buf, info.Message)
+	o.key("type")
+	appendJSONString(buf, info.Type)
+	if len(info.Chain) > 0 {
+		o.key("chain")
+		buf.WriteByte('[')
+		for i, c := range info.Chain {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			co := jsonObject{buf: buf}
+			co.open()
+			co.key("message")
+			appendJSONString(buf, c.Message)
+			co.key("type")
+			appendJSONString(buf, c.Type)
+			co.close()
+		}
+		buf.WriteByte(']')
+	}
+	if len(info.Fields) > 0 {
+		o.key("fields")
+		f := jsonObject{buf: buf}
+		f.open()
+		for _, n := range info.fieldNames() {
+			e.value(&f, n, info.Fields[n])
+		}
+		f.close()
+	}
+	o.close()
+} //jsonEncoder.error()
+
+// stack writes the frames as an array, or nothing when there are none
+func (e jsonEncoder) stack(j *jsonObject, key string, stack []Caller) {
+	if len(stack) == 0 || key == "-" {
+		return
+	}
+	j.key(key)
+	j.buf.WriteByte('[')
+	for i, c := range stack {
+		if i > 0 {
+			j.buf.WriteByte(',')
+		}
+		appendJSONCaller(j.buf, c)
+	}
+	j.buf.WriteByte(']')
+}
+
+func appendJSONCaller(buf *bytes.Buffer, c Caller) {
+	o := jsonObject{buf: buf}
+	o.open()
+	o.key("package")
+	appendJSONString(buf, c.Package())
+	o.key("file")
+	appendJSONString(buf, c.File())
+	o.key("line")
+	buf.WriteString(strconv.Itoa(c.Line()))
+	o.key("function")
+	appendJSONString(buf, c.Function())
+	o.close()
+}
+
+// reserved is true when name is used for one of the record fields
+func (e jsonEncoder) reserved(name string) bool {
+	switch name {
+	case e.config.TimeKey, e.config.LevelKey, e.config.NameKey, e.config.CallerKey, e.config.MessageKey, e.config.ResourceKey,
+		e.config.StackKey, e.config.ErrorStackKey, e.config.HeaderKey, e.config.DataKey:
+		return true
+	}
+	return false
+}
+
+// value writes "key":value for a data value
+func (e jsonEncoder) value(j *jsonObject, key string, v interface{}) {
+	switch tv := v.(type) {
+	case nil:
+		j.key(key)
+		j.buf.WriteString("null")
+	case string:
+		j.key(key)
+		appendJSONString(j.buf, tv)
+	case bool:
+		j.key(key)
+		j.buf.WriteString(strconv.FormatBool(tv))
+	case int:
+		j.key(key)
+		j.buf.WriteString(strconv.Itoa(tv))
+	case int64:
+		j.key(key)
+		j.buf.WriteString(strconv.FormatInt(tv, 10))
+	case error:
+		//most errors marshal to {} so rather write the text
+		j.key(key)
+		appendJSONString(j.buf, tv.Error())
+	default:
+		jsonValue, err := json.Marshal(v)
+		if err != nil {
+			switch e.config.OnMarshalError {
+			case MarshalErrorOmit:
+				return
+			case MarshalErrorReport:
+				j.key(key)
+				appendJSONString(j.buf, "!ERROR: "+err.Error())
+			default:
+				j.key(key)
+				appendJSONString(j.buf, fmt.Sprintf("%+v", v))
+			}
+			return
+		}
+		j.key(key)
+		j.buf.Write(jsonValue)
+	}
+} //jsonEncoder.value()
+
+// jsonObject writes the punctuation of an object into buf
+type jsonObject struct {
+	buf   *bytes.Buffer
+	count int
+}
+
+func (j *jsonObject) open()  { j.buf.WriteByte('{') }
+func (j *jsonObject) close() { j.buf.WriteByte('}') }
+
+func (j *jsonObject) key(k string) {
+	if j.count > 0 {
+		j.buf.WriteByte(',')
+	}
+	j.count++
+	appendJSONString(j.buf, k)
+	j.buf.WriteByte(':')
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString writes s as a quoted JSON string
+func appendJSONString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' {
+				i++
+				continue
+			}
+			buf.WriteString(s[start:i])
+			switch b {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(b)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[b>>4])
+				buf.WriteByte(hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`\ufffd`)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf.WriteString(s[start:])
+	buf.WriteByte('"')
+} //appendJSONString()
@@ -0,0 +1,62 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-msvc/logger"
+)
+
+func TestJSONWriter(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	l := logger.New().WithLevel(logger.LevelDebug)
+	l.SetWriter(logger.NewJSONWriter(buf, logger.JSONConfig{}))
+	l.With("email", "a@b.c").With("n", 5).With("err", errors.New("failed")).With("msg", "clash").Infof("hello \"world\"\n")
+	l.SetWriter(nil)
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &obj); err != nil {
+		t.Fatalf("invalid json: %+v: %s", err, buf.String())
+	}
+	t.Logf("json: %s", buf.String())
+	expected := map[string]interface{}{
+		"level":    "INFO",
+		"logger":   "github.com/go-msvc/logger_test",
+		"msg":      "hello \"world\"; ",
+		"email":    "a@b.c",
+		"n":        float64(5),
+		"err":      "failed",
+		"data.msg": "clash",
+	}
+	for n, v := range expected {
+		if obj[n] != v {
+			t.Fatalf("%s=(%T)%v != (%T)%v", n, obj[n], obj[n], v, v)
+		}
+	}
+	caller, ok := obj["caller"].(map[string]interface{})
+	if !ok || caller["function"] != "TestJSONWriter" || caller["package"] != "github.com/go-msvc/logger_test" {
+		t.Fatalf("wrong caller: %+v", obj["caller"])
+	}
+}
+
+func TestJSONWriterConfig(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	w := logger.NewJSONWriter(buf, logger.JSONConfig{
+		TimeKey:        "-",
+		CallerKey:      "-",
+		MessageKey:     "message",
+		DataKey:        "data",
+		OnMarshalError: logger.MarshalErrorOmit,
+	})
+	w.Write(logger.Record{
+		Level:   logger.LevelError,
+		Message: "m",
+		Data:    map[string]interface{}{"ok": true, "bad": make(chan int)},
+	})
+	if s := strings.TrimSpace(buf.String()); s != `{"level":"ERROR","logger":"","message":"m","data":{"ok":true}}` {
+		t.Fatalf("wrong json: %s", s)
+	}
+}
@@ -1,6 +1,11 @@
 package logger
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 type Level int
 
@@ -12,17 +17,138 @@ const (
 	LevelDefault //use parent's level
 )
 
+// levels added later take the values after LevelDefault so the values above do not change
+// the values are therefore not in order of severity, so compare levels with Enables()
+const (
+	LevelWarn  Level = iota + LevelDefault + 1
+	LevelTrace       //more detail than debug
+	LevelFatal       //logs, flushes the writers then exits
+	LevelPanic       //logs then panics
+)
+
+// stackOff is the stack level of names that capture no stacks, see SetStackOff()
+// it is not a level that can be set or parsed, so it only enables nothing
+const stackOff Level = -1
+
+// severity ranks the levels from most to least severe
+func (l Level) severity() int {
+	switch l {
+	case stackOff:
+		return -1
+	case LevelPanic:
+		return 0
+	case LevelFatal:
+		return 1
+	case LevelError:
+		return 2
+	case LevelWarn:
+		return 3
+	case LevelInfo:
+		return 4
+	case LevelDebug:
+		return 5
+	case LevelTrace:
+		return 6
+	}
+	return 7 //LevelDefault or not a level
+}
+
+// Enables says if a logger or writer with level l writes records with level r
+// i.e. r is as severe or more severe than l
+func (l Level) Enables(r Level) bool {
+	return r.severity() <= l.severity()
+}
+
+// valid says if l is one of the defined levels
+func (l Level) valid() bool {
+	return l == LevelDefault || (l.severity() >= 0 && l.severity() < 7)
+}
+
 func (l Level) String() string {
 	switch l {
+	case LevelPanic:
+		return "PANIC"
+	case LevelFatal:
+		return "FATAL"
 	case LevelError:
 		return "ERROR"
+	case LevelWarn:
+		return "WARN"
 	case LevelInfo:
 		return "INFO"
 	case LevelDebug:
 		return "DEBUG"
+	case LevelTrace:
+		return "TRACE"
 	case LevelDefault:
 		return "DEFAULT"
 	default:
 	}
 	return fmt.Sprintf("LEVEL(%v)", int(l))
 }
+
+// ParseLevel returns the level for a name (case-insensitive), an alias such as
+// "warning" or "err", or the numeric value of a level
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "panic":
+		return LevelPanic, nil
+	case "fatal":
+		return LevelFatal, nil
+	case "error", "err":
+		return LevelError, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "info", "information":
+		return LevelInfo, nil
+	case "debug", "dbg":
+		return LevelDebug, nil
+	case "trace":
+		return LevelTrace, nil
+	case "default", "":
+		return LevelDefault, nil
+	}
+	if i, err := strconv.Atoi(strings.TrimSpace(s)); err == nil && Level(i).valid() {
+		return Level(i), nil
+	}
+	return LevelDefault, fmt.Errorf("unknown log level %q", s)
+} //ParseLevel()
+
+func (l Level) MarshalText() ([]byte, error) {
+	if !l.valid() {
+		return []byte(strconv.Itoa(int(l))), nil
+	}
+	return []byte(strings.ToLower(l.String())), nil
+}
+
+func (l *Level) UnmarshalText(text []byte) error {
+	pl, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = pl
+	return nil
+}
+
+func (l Level) MarshalJSON() ([]byte, error) {
+	text, _ := l.MarshalText()
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON accepts a string or a number
+func (l *Level) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var i int
+		if err := json.Unmarshal(data, &i); err != nil {
+			return fmt.Errorf("log level must be a string or number")
+		}
+		s = strconv.Itoa(i)
+	}
+	return l.UnmarshalText([]byte(s))
+}
+
+// Set implements flag.Value so a level can be used with flag.Var()
+func (l *Level) Set(s string) error {
+	return l.UnmarshalText([]byte(s))
+}
@@ -0,0 +1,83 @@
+package logger_test
+
+import (
+	"encoding/json"
+	"flag"
+	"testing"
+
+	"github.com/go-msvc/logger"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		s     string
+		level logger.Level
+		ok    bool
+	}{
+		{"error", logger.LevelError, true},
+		{"ERR", logger.LevelError, true},
+		{"Warning", logger.LevelWarn, true},
+		{"warn", logger.LevelWarn, true},
+		{" info ", logger.LevelInfo, true},
+		{"debug", logger.LevelDebug, true},
+		{"trace", logger.LevelTrace, true},
+		{"fatal", logger.LevelFatal, true},
+		{"panic", logger.LevelPanic, true},
+		{"default", logger.LevelDefault, true},
+		{"off", logger.LevelDefault, false},
+		{"-1", logger.LevelDefault, false},
+		{"-2", logger.LevelDefault, false},
+		{"1", logger.LevelInfo, true},
+		{"5", logger.LevelTrace, true},
+		{"99", logger.LevelDefault, false},
+		{"verbose", logger.LevelDefault, false},
+	}
+	for index, test := range tests {
+		level, err := logger.ParseLevel(test.s)
+		if (err == nil) != test.ok || (test.ok && level != test.level) {
+			t.Fatalf("test[%d] ParseLevel(%q) -> %v,%v != %v", index, test.s, level, err, test.level)
+		}
+	}
+}
+
+func TestLevelMarshal(t *testing.T) {
+	var config struct {
+		Level  logger.Level `json:"level"`
+		Levels []logger.Level
+	}
+	if err := json.Unmarshal([]byte(`{"level":"Warning","Levels":["debug",0]}`), &config); err != nil {
+		t.Fatalf("failed: %+v", err)
+	}
+	if config.Level != logger.LevelWarn || len(config.Levels) != 2 || config.Levels[0] != logger.LevelDebug || config.Levels[1] != logger.LevelError {
+		t.Fatalf("wrong config: %+v", config)
+	}
+	jsonConfig, _ := json.Marshal(config)
+	if string(jsonConfig) != `{"level":"warn","Levels":["debug","error"]}` {
+		t.Fatalf("wrong json: %s", jsonConfig)
+	}
+	if err := json.Unmarshal([]byte(`{"level":"x"}`), &config); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	level := logger.LevelError
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&level, "level", "log level")
+	if err := fs.Parse([]string{"-level=debug"}); err != nil || level != logger.LevelDebug {
+		t.Fatalf("flag: %v %v", level, err)
+	}
+}
+
+func TestLevelValues(t *testing.T) {
+	//values of the original levels must not change, as they are used in config
+	if logger.LevelError != 0 || logger.LevelInfo != 1 || logger.LevelDebug != 2 || logger.LevelDefault != 3 {
+		t.Fatalf("level values changed")
+	}
+	order := []logger.Level{logger.LevelPanic, logger.LevelFatal, logger.LevelError, logger.LevelWarn, logger.LevelInfo, logger.LevelDebug, logger.LevelTrace}
+	for i, l := range order {
+		for j, r := range order {
+			if l.Enables(r) != (j <= i) {
+				t.Fatalf("%v.Enables(%v) = %v", l, r, l.Enables(r))
+			}
+		}
+	}
+}
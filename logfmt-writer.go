@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// LogfmtConfig controls how NewLogfmtEncoder() writes records
+type LogfmtConfig struct {
+	TimeFormat   string //default time.RFC3339Nano
+	CallerFormat string //verb used to format the caller= field: "%s"(default), "%S", "%f" or "%F" with optional width/precision
+	SortKeys     bool   //write data in sorted order rather than the order it was added to the logger
+	Resource     bool   //write Record.Resource as resource.<name>=<value> fields after the header
+}
+
+// NewLogfmtWriter makes a writer that writes records as key=value lines to w
+func NewLogfmtWriter(w io.Writer, c LogfmtConfig) IWriter {
+	return NewStreamWriter(w, NewLogfmtEncoder(c))
+}
+
+// NewLogfmtEncoder makes an encoder that writes records as key=value lines, e.g.:
+//
+//	time=2021-01-02T15:04:05.1Z level=INFO logger=github.com/a/b caller=b.go(12) msg="hello world" email=a@b.c
+//
+// an error set with WithError() is written after the message as error=<message> error.type=<type> etc.
+// then header fields that are set, e.g. trace_id=4bf9...
+// then stacks with frames separated by "; " when the record has them
+// data names that clash with the fields before them are written as "data.<name>"
+func NewLogfmtEncoder(c LogfmtConfig) IEncoder {
+	if c.TimeFormat == "" {
+		c.TimeFormat = time.RFC3339Nano
+	}
+	if c.CallerFormat == "" {
+		c.CallerFormat = "%s"
+	}
+	return logfmtEncoder{config: c}
+}
+
+type logfmtEncoder struct {
+	config LogfmtConfig
+}
+
+func (e logfmtEncoder) Encode(buf *bytes.Buffer, r Record) {
+	buf.WriteString("time=")
+	appendLogfmtValue(buf, r.Timestamp.Format(e.config.TimeFormat))
+	buf.WriteString(" level=")
+	buf.WriteString(r.Level.String())
+	buf.WriteString(" logger=")
+	appendLogfmtValue(buf, recordName(r))
+	if r.Caller != nil {
+		buf.WriteString(" caller=")
+		appendLogfmtValue(buf, fmt.Sprintf(e.config.CallerFormat, r.Caller))
+	}
+	buf.WriteString(" msg=")
+	appendLogfmtValue(buf, r.Message)
+	for _, f := range GetErrorInfo(r.Error).fields() {
+		buf.WriteByte(' ')
+		appendLogfmtKey(buf, f.name)
+		buf.WriteByte('=')
+		appendLogfmtValue(buf, f.value)
+	}
+	header := r.Header.fields()
+	for _, f := range header {
+		buf.WriteByte(' ')
+		buf.WriteString(f.name)
+		buf.WriteByte('=')
+		appendLogfmtValue(buf, f.value)
+	}
+	if len(r.Stack) > 0 {
+		buf.WriteString(" stack=")
+		appendLogfmtValue(buf, stackString(r.Stack, "; "))
+	}
+	if len(r.ErrorStack) > 0 {
+		buf.WriteString(" error_stack=")
+		appendLogfmtValue(buf, stackString(r.ErrorStack, "; "))
+	}
+	if e.config.Resource {
+		for _, f := range r.Resource.fields() {
+			buf.WriteString(" resource.")
+			buf.WriteString(f.name)
+			buf.WriteByte('=')
+			appendLogfmtValue(buf, f.value)
+		}
+	}
+	for _, n := range dataNames(r, e.config.SortKeys) {
+		e.key(buf, r, header, n)
+		e.value(buf, r.Data[n])
+	}
+	for _, f := range recordFields(r) {
+		e.key(buf, r, header, f.name)
+		if f.kind == fieldAny {
+			e.value(buf, f.any)
+		} else {
+			appendLogfmtValue(buf, f.text(e.config.TimeFormat))
+		}
+	}
+	buf.WriteByte('\n')
+} //logfmtEncoder.Encode()
+
+// key writes " name=" for data or a field, prefixed with "data." when the name is used by the record
+func (e logfmtEncoder) key(buf *bytes.Buffer, r Record, header []headerField, n string) {
+	buf.WriteByte(' ')
+	switch n {
+	case "time", "level", "logger", "caller", "msg", "stack", "error_stack":
+		buf.WriteString("data.")
+	case "error":
+		if r.Error != nil {
+			buf.WriteString("data.")
+		}
+	default:
+		if hasHeaderField(header, n) {
+			buf.WriteString("data.")
+		}
+	}
+	appendLogfmtKey(buf, n)
+	buf.WriteByte('=')
+}
+
+func (e logfmtEncoder) value(buf *bytes.Buffer, v interface{}) {
+	switch tv := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case string:
+		appendLogfmtValue(buf, tv)
+	case bool:
+		buf.WriteString(strconv.FormatBool(tv))
+	case int:
+		buf.WriteString(strconv.Itoa(tv))
+	case int64:
+		buf.WriteString(strconv.FormatInt(tv, 10))
+	case time.Time:
+		appendLogfmtValue(buf, tv.Format(e.config.TimeFormat))
+	case error:
+		appendLogfmtValue(buf, tv.Error())
+	default:
+		appendLogfmtValue(buf, fmt.Sprintf("%+v", v))
+	}
+}
+
+// appendLogfmtKey writes the key replacing characters that are not allowed with '_'
+func appendLogfmtKey(buf *bytes.Buffer, k string) {
+	if k == "" {
+		buf.WriteByte('_')
+		return
+	}
+	for _, c := range k {
+		if c <= ' ' || c == '=' || c == '"' || c == utf8.RuneError || c == 0x7f {
+			buf.WriteByte('_')
+		} else {
+			buf.WriteRune(c)
+		}
+	}
+}
+
+// appendLogfmtValue writes the value as is, or quoted when it is empty
+// or contains spaces, quotes, '=' or characters that are not printable
+func appendLogfmtValue(buf *bytes.Buffer, v string) {
+	if v == "" {
+		buf.WriteString(`""`)
+		return
+	}
+	if !utf8.ValidString(v) {
+		buf.WriteString(strconv.Quote(v))
+		return
+	}
+	for _, c := range v {
+		if c <= ' ' || c == '=' || c == '"' || !strconv.IsPrint(c) {
+			buf.WriteString(strconv.Quote(v))
+			return
+		}
+	}
+	buf.WriteString(v)
+}
@@ -0,0 +1,47 @@
+package logger_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-msvc/logger"
+)
+
+func TestLogfmtWriter(t *testing.T) {
+	ts := time.Date(2021, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		config   logger.LogfmtConfig
+		expected string
+	}{
+		{logger.LogfmtConfig{}, `time=2021-01-02T15:04:05Z level=INFO logger="" msg="hello world" z=1 email=a@b.c s="a \"b\"" e="" data.msg=x`},
+		{logger.LogfmtConfig{SortKeys: true, TimeFormat: "15:04"}, `time=15:04 level=INFO logger="" msg="hello world" e="" email=a@b.c data.msg=x s="a \"b\"" z=1`},
+	}
+	for index, test := range tests {
+		buf := bytes.NewBuffer(nil)
+		w := logger.NewLogfmtWriter(buf, test.config)
+		w.Write(logger.Record{
+			Timestamp: ts,
+			Level:     logger.LevelInfo,
+			Message:   "hello world",
+			Data:      map[string]interface{}{"z": 1, "email": "a@b.c", "s": `a "b"`, "e": "", "msg": "x"},
+			Keys:      []string{"z", "email", "s", "e", "msg"},
+		})
+		if s := strings.TrimSpace(buf.String()); s != test.expected {
+			t.Fatalf("test[%d]:\n\t%s !=\n\t%s", index, s, test.expected)
+		}
+	}
+}
+
+func TestLogfmtWriterCaller(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	l := logger.New().WithLevel(logger.LevelDebug)
+	l.SetWriter(logger.NewLogfmtWriter(buf, logger.LogfmtConfig{CallerFormat: "%F"}))
+	l.With("b", 2).With("a", 1).Debug("x")
+	l.SetWriter(nil)
+	s := buf.String()
+	if !strings.HasSuffix(s, " logger=github.com/go-msvc/logger_test caller=github.com/go-msvc/logger_test.TestLogfmtWriterCaller(41) msg=x b=2 a=1\n") {
+		t.Fatalf("wrong line: %s", s)
+	}
+}
@@ -1,7 +1,9 @@
 package logger
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 )
@@ -20,32 +22,88 @@ type Logger interface {
 
 	//SetXxx affects the named logger and all sub named loggers
 	//but does not change loggers already created in those names, just the names when they are used to create new loggers
-	SetWriter(newWriter IWriter) //sets the writer on this and all child loggers
-	SetLevel(newLevel Level)     //sets the level on named logger, for this logger and all NEW child loggers and existing child loggers that did not use WithLevel()
+	SetWriter(newWriter IWriter)  //sets the writer on this and all child loggers except those with a detached writer, nil to inherit the parent's writer
+	SetLevel(newLevel Level)      //sets the level on named logger, for this logger and all NEW child loggers and existing child loggers that did not use WithLevel()
+	SetStackLevel(newLevel Level) //records at or above the level capture stacks, LevelDefault to inherit, see SetGlobalStackLevel()
+	SetStackOff()                 //records capture no stacks, also when a parent captures them, until SetStackLevel()
+
+	//DetachWriter sets the writer like SetWriter() but keeps it when a parent calls SetWriter() or SetGlobalWriter() later
+	//e.g. to send one library's logs somewhere else while the global writer can still change
+	DetachWriter(newWriter IWriter)
+	ResetWriter() //removes the writer set on this name, including a detached writer, to use the writer of the nearest parent that sets one
+	ResetLevel()  //removes the level set on this name to inherit the parent's level, same as SetLevel(LevelDefault)
+
+	//SetLevelFor sets the level on the named logger like SetLevel() and restores the previous level after d
+	//calling it again before d ends replaces the level and end time, then the level from before the first call is restored
+	//calling SetLevel() before d ends keeps the new level and cancels the restore
+	SetLevelFor(newLevel Level, d time.Duration)
 
 	//WithXxx creates a copy of the logger with the new settings...
 	WithLevel(Level) Logger //only affects this new logger (can use LevelDefault to reset to named level and allow external control)
 	With(name string, value interface{}) Logger
+	WithFields(fields ...Field) Logger //adds fields without copying the data of With(), see Field
+	WithHeader(h Header) Logger        //header fields that are set in h replace those of the logger
+	WithError(err error) Logger        //the error written with records, rather than With("err", err)
 
 	Name() string
 	Names() []string
 	Level() Level
+	Header() Header
+
+	//fields are written with the record only, see Field
+	Log(level Level, msg string, fields ...Field)
+	Error(msg string, fields ...Field)
+	Warn(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Debug(msg string, fields ...Field)
+	Trace(msg string, fields ...Field)
 
-	Log(level Level, msg string)
-	Error(msg string)
-	Info(msg string)
-	Debug(msg string)
+	//Errorw logs an error record with err like WithError(err).Error(msg)
+	Errorw(err error, msg string, fields ...Field)
 
 	Logf(level Level, format string, args ...interface{})
 	Errorf(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
 	Infof(format string, args ...interface{})
 	Debugf(format string, args ...interface{})
+	Tracef(format string, args ...interface{})
+
+	//Ctx returns a copy of the logger with the values registered with RegisterContextKey() or
+	//RegisterContextField() that are in ctx added as data and the header of ContextWithHeader() merged
+	//into its header, and XxxCtx() log with those values
+	Ctx(ctx context.Context) Logger
+
+	LogCtx(ctx context.Context, level Level, msg string, fields ...Field)
+	ErrorCtx(ctx context.Context, msg string, fields ...Field)
+	WarnCtx(ctx context.Context, msg string, fields ...Field)
+	InfoCtx(ctx context.Context, msg string, fields ...Field)
+	DebugCtx(ctx context.Context, msg string, fields ...Field)
+	TraceCtx(ctx context.Context, msg string, fields ...Field)
+
+	LogfCtx(ctx context.Context, level Level, format string, args ...interface{})
+	ErrorfCtx(ctx context.Context, format string, args ...interface{})
+	WarnfCtx(ctx context.Context, format string, args ...interface{})
+	InfofCtx(ctx context.Context, format string, args ...interface{})
+	DebugfCtx(ctx context.Context, format string, args ...interface{})
+	TracefCtx(ctx context.Context, format string, args ...interface{})
+
+	//Fatal logs, flushes all writers then exits the program with status 1
+	Fatal(msg string, fields ...Field)
+	Fatalf(format string, args ...interface{})
+
+	//Panic logs then panics with the message
+	Panic(msg string, fields ...Field)
+	Panicf(format string, args ...interface{})
 }
 
 type logger struct {
-	named *named
-	level Level
-	data  map[string]interface{}
+	named  *named
+	level  Level
+	data   map[string]interface{}
+	keys   []string //names in data in the order they were added
+	header Header
+	err    error
+	fields []Field //appended by WithFields()
 }
 
 func (l logger) New(name string) Logger {
@@ -56,19 +114,43 @@ func (l logger) SetWriter(newWriter IWriter) {
 	l.named.setWriter(newWriter)
 }
 
+func (l logger) DetachWriter(newWriter IWriter) {
+	l.named.detachWriter(newWriter)
+}
+
+func (l logger) ResetWriter() {
+	l.named.resetWriter()
+}
+
+func (l logger) ResetLevel() {
+	l.named.setLevel(LevelDefault)
+}
+
 func (l logger) SetLevel(newLevel Level) {
 	l.level = LevelDefault //clear own setting and use named's level...
 	l.named.setLevel(newLevel)
 }
 
+func (l logger) SetStackLevel(newLevel Level) {
+	l.named.setStackLevel(newLevel)
+}
+
+func (l logger) SetStackOff() {
+	l.named.setStackLevel(stackOff)
+}
+
+func (l logger) SetLevelFor(newLevel Level, d time.Duration) {
+	l.named.setLevelFor(newLevel, d)
+}
+
 func (l logger) Name() string    { return l.named.name }
 func (l logger) Names() []string { return l.named.names }
 
 func (l logger) Level() Level {
-	if l.level < LevelDefault {
+	if l.level != LevelDefault {
 		return l.level //fall through to use named logger's level
 	}
-	return l.named.level
+	return l.named.Level()
 }
 
 func (l logger) String() string { return l.named.name }
@@ -84,14 +166,41 @@ func (l logger) With(name string, value interface{}) Logger {
 	for n, v := range d {
 		l.data[n] = v
 	}
+	if _, ok := d[name]; !ok {
+		l.keys = append(l.keys[:len(l.keys):len(l.keys)], name)
+	}
 	l.data[name] = value
 	return l
 }
 
-func (l logger) Log(level Level, msg string) { l.log(3, level, msg) }
-func (l logger) Error(msg string)            { l.log(3, LevelError, msg) }
-func (l logger) Info(msg string)             { l.log(3, LevelInfo, msg) }
-func (l logger) Debug(msg string)            { l.log(3, LevelDebug, msg) }
+func (l logger) WithHeader(h Header) Logger {
+	l.header = l.header.Merge(h)
+	return l
+}
+
+func (l logger) Header() Header { return l.header }
+
+func (l logger) WithError(err error) Logger {
+	l.err = err
+	return l
+}
+
+func (l logger) Errorw(err error, msg string, fields ...Field) {
+	l.err = err
+	l.log(3, LevelError, msg, fields)
+}
+
+func (l logger) WithFields(fields ...Field) Logger {
+	l.fields = append(l.fields[:len(l.fields):len(l.fields)], fields...)
+	return l
+}
+
+func (l logger) Log(level Level, msg string, fields ...Field) { l.log(3, level, msg, fields) }
+func (l logger) Error(msg string, fields ...Field)            { l.log(3, LevelError, msg, fields) }
+func (l logger) Warn(msg string, fields ...Field)             { l.log(3, LevelWarn, msg, fields) }
+func (l logger) Info(msg string, fields ...Field)             { l.log(3, LevelInfo, msg, fields) }
+func (l logger) Debug(msg string, fields ...Field)            { l.log(3, LevelDebug, msg, fields) }
+func (l logger) Trace(msg string, fields ...Field)            { l.log(3, LevelTrace, msg, fields) }
 
 func (l logger) Logf(level Level, format string, args ...interface{}) {
 	l.logf(4, level, format, args...)
@@ -101,6 +210,10 @@ func (l logger) Errorf(format string, args ...interface{}) {
 	l.logf(4, LevelError, format, args...)
 }
 
+func (l logger) Warnf(format string, args ...interface{}) {
+	l.logf(4, LevelWarn, format, args...)
+}
+
 func (l logger) Infof(format string, args ...interface{}) {
 	l.logf(4, LevelInfo, format, args...)
 }
@@ -109,23 +222,72 @@ func (l logger) Debugf(format string, args ...interface{}) {
 	l.logf(4, LevelDebug, format, args...)
 }
 
-func (l logger) log(depth int, level Level, msg string) {
-	if level <= l.Level() {
-		l.named.writer.Write(
-			Record{
-				Caller:    GetCaller(depth),
-				Timestamp: time.Now(),
-				Logger:    l,
-				Level:     level,
-				Message:   strings.ReplaceAll(msg, "\n", "; "),
-				Data:      l.data,
-			},
-		)
+func (l logger) Tracef(format string, args ...interface{}) {
+	l.logf(4, LevelTrace, format, args...)
+}
+
+func (l logger) Fatal(msg string, fields ...Field) {
+	l.log(3, LevelFatal, msg, fields)
+	l.exit()
+}
+
+func (l logger) Fatalf(format string, args ...interface{}) {
+	l.logf(4, LevelFatal, format, args...)
+	l.exit()
+}
+
+func (l logger) Panic(msg string, fields ...Field) {
+	l.log(3, LevelPanic, msg, fields)
+	panic(msg)
+}
+
+func (l logger) Panicf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	l.log(3, LevelPanic, msg, nil)
+	panic(msg)
+}
+
+// fatalFlushTimeout limits how long a fatal log waits for the writers to flush
+const fatalFlushTimeout = 5 * time.Second
+
+func (l logger) exit() {
+	ctx, cancel := context.WithTimeout(context.Background(), fatalFlushTimeout)
+	Flush(ctx)
+	cancel()
+	os.Exit(1)
+}
+
+func (l logger) log(depth int, level Level, msg string, fields []Field) {
+	if l.Level().Enables(level) {
+		if len(l.fields) > 0 {
+			fields = append(l.fields[:len(l.fields):len(l.fields)], fields...)
+		}
+		r := Record{
+			Caller:    GetCaller(depth),
+			Timestamp: time.Now(),
+			Logger:    l,
+			Level:     level,
+			Message:   strings.ReplaceAll(msg, "\n", "; "),
+			Data:      l.data,
+			Keys:      l.keys,
+			Error:     l.err,
+			Fields:    fields,
+			Header:    l.header,
+			Resource:  resource.Load().(*Resource),
+		}
+		if l.named.StackLevel().Enables(level) {
+			r.Stack = GetStack(depth)
+			r.ErrorStack = ErrorStack(l.err)
+			if r.ErrorStack == nil {
+				r.ErrorStack = dataErrorStack(r)
+			}
+		}
+		l.named.getWriter().Write(r)
 	}
 }
 
 func (l logger) logf(depth int, level Level, format string, args ...interface{}) {
-	if level <= l.Level() {
-		l.log(depth, level, fmt.Sprintf(format, args...))
+	if l.Level().Enables(level) {
+		l.log(depth, level, fmt.Sprintf(format, args...), nil)
 	}
 }
@@ -1,7 +1,11 @@
 package logger_test
 
 import (
+	"bytes"
+	"os"
+	"os/exec"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/go-msvc/logger"
@@ -9,14 +13,19 @@ import (
 )
 
 type testWriter struct {
+	sync.Mutex
 	records []logger.Record
 }
 
 func (w *testWriter) Write(r logger.Record) {
+	w.Lock()
+	defer w.Unlock()
 	w.records = append(w.records, r)
 }
 
 func (w *testWriter) Reset() {
+	w.Lock()
+	defer w.Unlock()
 	w.records = []logger.Record{}
 }
 
@@ -140,3 +149,151 @@ func showLogger(t *testing.T, l logger.INamed) {
 		showLogger(t, l)
 	}
 }
+
+func TestLevels(t *testing.T) {
+	w := &testWriter{records: []logger.Record{}}
+	l := logger.New().WithLevel(logger.LevelWarn)
+	l.SetWriter(w)
+	l.Tracef("1")
+	l.Debugf("2")
+	l.Info("3")
+	l.Warnf("4")
+	l.Error("5")
+	l = l.WithLevel(logger.LevelTrace)
+	l.Trace("6")
+	l.SetWriter(nil)
+
+	w.assert(t, 0, "TestLevels", l.Name(), "WARN", "4", nil)
+	w.assert(t, 1, "TestLevels", l.Name(), "ERROR", "5", nil)
+	w.assert(t, 2, "TestLevels", l.Name(), "TRACE", "6", nil)
+	if len(w.records) != 3 {
+		t.Fatalf("%d records", len(w.records))
+	}
+}
+
+func TestPanic(t *testing.T) {
+	w := &testWriter{records: []logger.Record{}}
+	l := logger.New()
+	l.SetWriter(w)
+	defer l.SetWriter(nil)
+	defer func() {
+		if r := recover(); r != "oops 1" {
+			t.Fatalf("recovered %v", r)
+		}
+		w.assert(t, 0, "TestPanic", l.Name(), "PANIC", "oops 1", nil)
+	}()
+	l.Panicf("oops %d", 1)
+}
+
+func TestFatal(t *testing.T) {
+	if os.Getenv("LOGGER_TEST_FATAL") == "1" {
+		logger.New().Fatalf("bye %d", 1)
+		return
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFatal$")
+	cmd.Env = append(os.Environ(), "LOGGER_TEST_FATAL=1")
+	stderr := bytes.NewBuffer(nil)
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	if e, ok := err.(*exec.ExitError); !ok || e.ExitCode() != 1 {
+		t.Fatalf("expected exit 1, got %v", err)
+	}
+	if !strings.Contains(stderr.String(), "FATAL") || !strings.Contains(stderr.String(), "bye 1") {
+		t.Fatalf("fatal not logged: %s", stderr.String())
+	}
+}
+
+func TestNamedTree(t *testing.T) {
+	a := logger.Named("example.com/org/a")
+	b := logger.Named("example.com/org/b/pkg")
+	other := logger.Named("example.org/x")
+
+	logger.Named("example.com/org").SetLevel(logger.LevelDebug)
+	if a.Level() != logger.LevelDebug || b.Level() != logger.LevelDebug || other.Level() != logger.LevelError {
+		t.Fatalf("prefix level not applied: a=%s b=%s other=%s", a.Level(), b.Level(), other.Level())
+	}
+
+	//deeper node overrides and is not changed by the prefix
+	logger.Named("example.com/org/b").SetLevel(logger.LevelInfo)
+	logger.Named("example.com/org").SetLevel(logger.LevelTrace)
+	if a.Level() != logger.LevelTrace || b.Level() != logger.LevelInfo {
+		t.Fatalf("override not kept: a=%s b=%s", a.Level(), b.Level())
+	}
+
+	//back to inheriting
+	logger.Named("example.com/org/b").SetLevel(logger.LevelDefault)
+	if b.Level() != logger.LevelTrace {
+		t.Fatalf("b=%s", b.Level())
+	}
+	logger.Named("example.com").SetLevel(logger.LevelDefault)
+	logger.Named("example.com/org").SetLevel(logger.LevelDefault)
+	if a.Level() != logger.LevelError {
+		t.Fatalf("a=%s", a.Level())
+	}
+
+	if b.Name() != "example.com/org/b/pkg" || strings.Join(b.Names(), ",") != "example.com,org,b,pkg" {
+		t.Fatalf("name=%s names=%v", b.Name(), b.Names())
+	}
+	org, ok := logger.All()["example.com"].All()["org"]
+	if !ok || len(org.All()) != 2 || org.All()["b"].All()["pkg"] == nil {
+		t.Fatalf("tree not walked")
+	}
+}
+
+func TestDetachWriter(t *testing.T) {
+	global := &testWriter{records: []logger.Record{}}
+	lib := &testWriter{records: []logger.Record{}}
+	libLogger := logger.Named("example.com/detach/lib/pkg")
+	appLogger := logger.Named("example.com/detach/app")
+
+	logger.Named("example.com/detach/lib").DetachWriter(lib)
+	logger.SetGlobalWriter(global)
+	defer logger.SetGlobalWriter(nil)
+	libLogger.Error("lib 1")
+	appLogger.Error("app 1")
+	logger.Named("example.com/detach/lib/new").Error("lib 2") //created after detach
+	if len(lib.records) != 2 || len(global.records) != 1 || global.records[0].Message != "app 1" {
+		t.Fatalf("lib=%d global=%d", len(lib.records), len(global.records))
+	}
+
+	//plain SetWriter below the detached name is replaced when the detached name sets its writer again
+	libLogger.SetWriter(global)
+	libLogger.Error("lib 3")
+	logger.Named("example.com/detach/lib").DetachWriter(lib)
+	libLogger.Error("lib 4")
+	if len(lib.records) != 3 || len(global.records) != 2 || lib.records[2].Message != "lib 4" {
+		t.Fatalf("lib=%d global=%d", len(lib.records), len(global.records))
+	}
+
+	//reset inherits the global writer again
+	logger.Named("example.com/detach/lib").ResetWriter()
+	libLogger.Error("lib 5")
+	if len(lib.records) != 3 || global.records[len(global.records)-1].Message != "lib 5" {
+		t.Fatalf("lib=%d global=%d", len(lib.records), len(global.records))
+	}
+
+	//SetWriter(nil) inherits the parent's writer
+	appLogger.SetWriter(lib)
+	appLogger.SetWriter(nil)
+	appLogger.Error("app 2")
+	if len(lib.records) != 3 || global.records[len(global.records)-1].Message != "app 2" {
+		t.Fatalf("lib=%d global=%d", len(lib.records), len(global.records))
+	}
+}
+
+func TestResetLevel(t *testing.T) {
+	l := logger.Named("example.com/reset/a")
+	l.SetLevel(logger.LevelDebug)
+	logger.Named("example.com/reset").SetLevel(logger.LevelWarn)
+	if l.Level() != logger.LevelDebug {
+		t.Fatalf("level=%s", l.Level())
+	}
+	l.ResetLevel()
+	if l.Level() != logger.LevelWarn {
+		t.Fatalf("level=%s after reset", l.Level())
+	}
+	logger.Named("example.com/reset").ResetLevel()
+	if l.Level() != logger.LevelError {
+		t.Fatalf("level=%s after reset of parent", l.Level())
+	}
+}
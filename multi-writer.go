@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// RecordFilter returns true for records that a branch of a multi writer must write
+type RecordFilter func(r Record) bool
+
+// MultiBranch is one of the writers of a multi writer
+type MultiBranch struct {
+	Writer  IWriter
+	Level   Level          //write records with this level or more severe, LevelDefault for all levels
+	Filters []RecordFilter //write only records for which all filters return true
+}
+
+// Branch makes a branch for NewMultiWriter(), e.g.
+//
+//	NewMultiWriter(
+//		Branch(fileWriter, LevelError),
+//		Branch(consoleWriter, LevelDebug, NameFilter("github.com/a/b/...")),
+//	)
+func Branch(w IWriter, level Level, filters ...RecordFilter) MultiBranch {
+	return MultiBranch{Writer: w, Level: level, Filters: filters}
+}
+
+// NewMultiWriter makes a writer that writes each record to all the branches that accept it
+// a branch whose writer or filter panics is reported on stderr and does not stop the other branches
+// Flush and Close are passed on to each branch's writer
+func NewMultiWriter(branches ...MultiBranch) IWriter {
+	mw := &multiWriter{}
+	for _, b := range branches {
+		if b.Writer != nil {
+			mw.branches = append(mw.branches, b)
+		}
+	}
+	return mw
+}
+
+type multiWriter struct {
+	branches []MultiBranch
+}
+
+func (mw *multiWriter) Write(r Record) {
+	for i, b := range mw.branches {
+		if b.Level != LevelDefault && !b.Level.Enables(r.Level) {
+			continue
+		}
+		mw.write(i, r)
+	}
+}
+
+func (b MultiBranch) accept(r Record) bool {
+	for _, f := range b.Filters {
+		if !f(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// write writes to one branch if its filters accept the record and recovers if it panics
+func (mw *multiWriter) write(i int, r Record) {
+	defer func() {
+		if p := recover(); p != nil {
+			fmt.Fprintf(os.Stderr, "logger: multi writer branch %d (%T) panic: %v\n", i, mw.branches[i].Writer, p)
+		}
+	}()
+	if mw.branches[i].accept(r) {
+		mw.branches[i].Writer.Write(r)
+	}
+}
+
+func (mw *multiWriter) Flush(ctx context.Context) error {
+	errs := errorList{}
+	for i, b := range mw.branches {
+		if f, ok := b.Writer.(IFlusher); ok {
+			if err := mw.call(i, func() error { return f.Flush(ctx) }); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (mw *multiWriter) Close() error {
+	errs := errorList{}
+	for i, b := range mw.branches {
+		if c, ok := b.Writer.(ICloser); ok {
+			if err := mw.call(i, c.Close); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (mw *multiWriter) Writers() []IWriter {
+	list := make([]IWriter, len(mw.branches))
+	for i, b := range mw.branches {
+		list[i] = b.Writer
+	}
+	return list
+}
+
+// a multi writer has nothing of its own to flush or close
+func (mw *multiWriter) flushOwn(ctx context.Context) error { return nil }
+func (mw *multiWriter) closeOwn() error                    { return nil }
+
+// call calls f and returns a panic as an error
+func (mw *multiWriter) call(i int, f func() error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("multi writer branch %d (%T) panic: %v", i, mw.branches[i].Writer, p)
+		}
+	}()
+	return f()
+}
+
+// NameFilter accepts records from loggers with one of the names
+// a name can be "a/b/..." for a/b and all names below it, or a path.Match pattern
+func NameFilter(names ...string) RecordFilter {
+	return func(r Record) bool {
+		name := recordName(r)
+		for _, n := range names {
+			if matchPackage(n, name) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// PackageFilter accepts records logged from code in one of the packages
+// a package can be "a/b/..." for a/b and all packages below it, or a path.Match pattern
+func PackageFilter(packages ...string) RecordFilter {
+	return func(r Record) bool {
+		if r.Caller == nil {
+			return false
+		}
+		pkg := r.Caller.Package()
+		for _, p := range packages {
+			if matchPackage(p, pkg) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// DataFilter accepts records that have data or a field with one of the names
+func DataFilter(names ...string) RecordFilter {
+	return func(r Record) bool {
+		for _, n := range names {
+			if _, ok := r.Data[n]; ok || hasField(r.Fields, n) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// NotFilter accepts the records that f does not accept
+func NotFilter(f RecordFilter) RecordFilter {
+	return func(r Record) bool { return !f(r) }
+}
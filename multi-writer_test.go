@@ -0,0 +1,77 @@
+package logger_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-msvc/logger"
+)
+
+type panicWriter struct{}
+
+func (panicWriter) Write(r logger.Record) { panic("broken") }
+
+func TestMultiWriter(t *testing.T) {
+	errors := &lifecycleWriter{}
+	console := &lifecycleWriter{}
+	audit := &testWriter{}
+	filtered := &testWriter{}
+	panicFilter := func(r logger.Record) bool { panic("broken filter") }
+	mw := logger.NewMultiWriter(
+		logger.Branch(panicWriter{}, logger.LevelDefault),
+		logger.Branch(filtered, logger.LevelDefault, panicFilter),
+		logger.Branch(errors, logger.LevelError),
+		logger.Branch(console, logger.LevelDebug, logger.NameFilter("example.com/multi/a/..."), logger.NotFilter(logger.DataFilter("secret"))),
+		logger.Branch(audit, logger.LevelDefault, logger.DataFilter("user")),
+	)
+	a := logger.Named("example.com/multi/a/sub")
+	a.SetWriter(mw)
+	a.SetLevel(logger.LevelTrace)
+	b := logger.Named("example.com/multi/b")
+	b.SetWriter(mw)
+	b.SetLevel(logger.LevelTrace)
+
+	a.Errorf("a error")
+	a.Debugf("a debug")
+	a.Tracef("a trace")
+	a.With("secret", "x").Infof("a secret")
+	b.Errorf("b error")
+	b.With("user", "joe").Tracef("b user")
+
+	check := func(name string, w *testWriter, expected ...string) {
+		if len(w.records) != len(expected) {
+			t.Fatalf("%s: %d records, expected %d", name, len(w.records), len(expected))
+		}
+		for i, m := range expected {
+			if w.records[i].Message != m {
+				t.Fatalf("%s[%d]: %q, expected %q", name, i, w.records[i].Message, m)
+			}
+		}
+	}
+	check("errors", &errors.testWriter, "a error", "b error")
+	check("console", &console.testWriter, "a error", "a debug")
+	check("audit", audit, "b user")
+	check("filtered", filtered)
+
+	if err := logger.Flush(context.Background()); err != nil || errors.flushed != 1 || console.flushed != 1 {
+		t.Fatalf("flush: %v", err)
+	}
+	a.SetWriter(nil)
+	if errors.closed != 0 || console.closed != 0 {
+		t.Fatalf("closed while b uses it: %d %d", errors.closed, console.closed)
+	}
+	b.SetWriter(nil)
+	if errors.closed != 1 || console.closed != 1 {
+		t.Fatalf("not closed when replaced: %d %d", errors.closed, console.closed)
+	}
+}
+
+func TestMultiWriterPackageFilter(t *testing.T) {
+	w := &testWriter{}
+	mw := logger.NewMultiWriter(logger.Branch(w, logger.LevelDefault, logger.PackageFilter("github.com/go-msvc/...")))
+	mw.Write(logger.Record{Caller: logger.GetCaller(1), Message: "here"})
+	mw.Write(logger.Record{Message: "no caller"})
+	if len(w.records) != 1 || w.records[0].Message != "here" {
+		t.Fatalf("records: %+v", w.records)
+	}
+}
@@ -1,20 +1,64 @@
 package logger
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// named loggers form a tree by path segments of their names
+// e.g. "github.com/go-msvc/logger" is node "logger" in "go-msvc" in "github.com"
+// so setting the level of "github.com/go-msvc" applies to all packages below it
+// except those that set their own level
+//
+// the level and writer in use are read without locking when logging, and
+// all changes to the tree are made while holding treeMutex
 type named struct {
-	name  string
-	names []string
+	name  string   //full name, e.g. "github.com/go-msvc/logger"
+	names []string //path segments, e.g. ["github.com","go-msvc","logger"]
 
-	level Level
+	ownLevel Level //level set on this name, or LevelDefault to inherit the parent's level
+	level    int32 //Level in use: ownLevel or inherited, accessed atomically
+
+	ownStackLevel Level //stack level set on this name, stackOff for none, or LevelDefault to inherit the parent's
+	stackLevel    int32 //Level in use for stack capture, accessed atomically
 
-	sync.Mutex
 	parent *named
 	subs   map[string]*named
 
-	writer IWriter
+	ownWriter IWriter      //writer set on this name, or nil to inherit the parent's writer
+	detached  bool         //ownWriter was set with detachWriter() and is kept when a parent sets its writer
+	writer    atomic.Value //writerValue in use: ownWriter or inherited
+
+	escalation *escalation //temporary level set with setLevelFor()
+}
+
+// treeMutex protects the structure of the tree and the settings of all named loggers
+// readers that only need the level or writer in use do not lock
+var treeMutex sync.RWMutex
+
+// writerValue wraps the writer so that atomic.Value always stores the same type
+type writerValue struct {
+	IWriter
+}
+
+func newNamed(name string, names []string, parent *named, level Level, stackLevel Level, writer IWriter) *named {
+	l := &named{
+		name:          name,
+		names:         names,
+		ownLevel:      LevelDefault,
+		level:         int32(level),
+		ownStackLevel: LevelDefault,
+		stackLevel:    int32(stackLevel),
+		parent:        parent,
+		subs:          map[string]*named{},
+	}
+	l.writer.Store(writerValue{writer})
+	return l
 }
 
 func (l *named) New(name string) Logger {
@@ -22,21 +66,12 @@ func (l *named) New(name string) Logger {
 		return top.New(name)
 	}
 
-	l.Lock()
-	defer l.Unlock()
-	nl, found := l.subs[name]
-	if !found {
-		nl = &named{
-			name:   name,
-			names:  append(l.names, name),
-			parent: l,
-			subs:   map[string]*named{},
-			level:  l.level,
-			writer: l.writer,
+	nl := l
+	for _, segment := range strings.Split(name, "/") {
+		if segment != "" {
+			nl = nl.sub(segment)
 		}
-		l.subs[name] = nl
 	}
-
 	return logger{
 		named: nl,
 		data:  map[string]interface{}{},
@@ -44,32 +79,200 @@ func (l *named) New(name string) Logger {
 	}
 } //named.New()
 
+// sub returns the named sub node, creating it if it does not yet exist
+func (l *named) sub(segment string) *named {
+	treeMutex.RLock()
+	nl, found := l.subs[segment]
+	treeMutex.RUnlock()
+	if found {
+		return nl
+	}
+
+	treeMutex.Lock()
+	defer treeMutex.Unlock()
+	nl, found = l.subs[segment]
+	if !found {
+		names := make([]string, len(l.names), len(l.names)+1)
+		copy(names, l.names)
+		names = append(names, segment)
+		nl = newNamed(strings.Join(names, "/"), names, l, l.Level(), l.StackLevel(), l.getWriter())
+		l.subs[segment] = nl
+	}
+	return nl
+} //named.sub()
+
+// getWriter returns the writer in use
+func (l *named) getWriter() IWriter {
+	return l.writer.Load().(writerValue).IWriter
+}
+
+// setWriter sets the writer on l and all its subs, except subs with a detached writer
+// a nil writer makes l inherit its parent's writer, or use the default writer on top
 func (l *named) setWriter(newWriter IWriter) {
-	if newWriter == nil {
-		newWriter = defaultWriter{}
+	l.updateWriter(func() {
+		l.clearWriters()
+		l.ownWriter = newWriter
+	})
+}
+
+// detachWriter sets the writer on l and all its subs like setWriter()
+// but l keeps it when a parent sets its writer later, until l.resetWriter()
+func (l *named) detachWriter(newWriter IWriter) {
+	l.updateWriter(func() {
+		l.clearWriters()
+		l.ownWriter = newWriter
+		l.detached = newWriter != nil
+	})
+}
+
+// resetWriter makes l inherit the writer of its parent
+func (l *named) resetWriter() {
+	l.updateWriter(func() {
+		l.ownWriter = nil
+		l.detached = false
+	})
+}
+
+// clearWriters makes all subs of l inherit their writer, except those with a detached writer
+// must be called with treeMutex locked
+func (l *named) clearWriters() {
+	for _, sub := range l.subs {
+		if !sub.detached {
+			sub.ownWriter = nil
+			sub.clearWriters()
+		}
 	}
+}
 
-	l.Lock()
-	defer l.Unlock()
+// updateWriter calls update() to change the writer settings of l and its subs
+// then updates the writer in use for l and all sub names that inherit it
+// writers that no logger uses any more, also not inside another writer, are flushed and closed
+func (l *named) updateWriter(update func()) {
+	treeMutex.Lock()
+	defer treeMutex.Unlock()
+	before := writerSet{}
+	top.writers(&before)
+	update()
+	var inherited IWriter = defaultWriter{} //top has no parent to inherit from
+	if l.parent != nil {
+		inherited = l.parent.getWriter()
+	}
+	l.inheritWriter(inherited)
+	after := writerSet{}
+	top.writers(&after)
+
+	//closed before unlocking, so that another SetWriter() cannot use them again meanwhile
+	//writers that cannot be compared are not closed, as they may still be in use
+	replaced := []IWriter{}
+	for _, w := range before.ordered() {
+		if comparable(w) && !after.has(w) {
+			replaced = append(replaced, w)
+		}
+	}
+	if len(replaced) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), replacedCloseTimeout)
+		defer cancel()
+		if err := closeWriters(ctx, replaced); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: closing replaced writer: %v\n", err)
+		}
+	}
+} //named.updateWriter()
+
+// replacedCloseTimeout limits how long SetWriter() waits for replaced writers to flush
+const replacedCloseTimeout = 5 * time.Second
+
+// writers adds the writers used by l and its subs to the set
+// must be called with treeMutex locked
+func (l *named) writers(set *writerSet) {
+	set.add(l.getWriter())
+	for _, sub := range l.subs {
+		sub.writers(set)
+	}
+}
+
+// inheritWriter updates the writer in use from the parent's writer
+// and all sub names that inherit it
+// must be called with treeMutex locked
+func (l *named) inheritWriter(parentWriter IWriter) {
+	w := l.ownWriter
+	if w == nil {
+		w = parentWriter
+	}
+	l.writer.Store(writerValue{w})
+	for _, sub := range l.subs {
+		sub.inheritWriter(w)
+	}
+}
+
+// resetWriters removes the writers set on l and all its subs, including detached writers
+// then uses the default writer everywhere
+// must be called with treeMutex locked
+func (l *named) resetWriters() {
+	l.ownWriter = nil
+	l.detached = false
+	l.writer.Store(writerValue{defaultWriter{}})
 	for _, sub := range l.subs {
-		sub.setWriter(newWriter)
+		sub.resetWriters()
 	}
-	l.writer = newWriter
 }
 
+// setLevel sets the level of this name, or LevelDefault to inherit the parent's level
+// and updates the level of all sub names that inherit it
+// it also cancels a temporary level set with setLevelFor()
 func (l *named) setLevel(newLevel Level) {
-	l.Lock()
-	defer l.Unlock()
+	l.updateLevel(func() {
+		l.stopEscalation()
+		l.ownLevel = newLevel
+	})
+}
+
+// setStackLevel sets the stack level of this name, stackOff for none, or LevelDefault to inherit the parent's level
+// and updates the stack level of all sub names that inherit it
+func (l *named) setStackLevel(newLevel Level) {
+	l.updateLevel(func() {
+		l.ownStackLevel = newLevel
+	})
+}
+
+// updateLevel calls update() to change l.ownLevel or l.ownStackLevel
+// then updates the levels in use for l and all sub names that inherit them
+func (l *named) updateLevel(update func()) {
+	treeMutex.Lock()
+	defer treeMutex.Unlock()
+	update()
+	inherited, inheritedStack := LevelError, stackOff //top has no parent to inherit from
+	if l.parent != nil {
+		inherited, inheritedStack = l.parent.Level(), l.parent.StackLevel()
+	}
+	l.inherit(inherited, inheritedStack)
+}
+
+// inherit updates the levels in use from the parent's levels
+// and all sub names that inherit them
+// must be called with treeMutex locked
+func (l *named) inherit(parentLevel, parentStackLevel Level) {
+	level := l.ownLevel
+	if level == LevelDefault {
+		level = parentLevel
+	}
+	stackLevel := l.ownStackLevel
+	if stackLevel == LevelDefault {
+		stackLevel = parentStackLevel
+	}
+	atomic.StoreInt32(&l.level, int32(level))
+	atomic.StoreInt32(&l.stackLevel, int32(stackLevel))
 	for _, sub := range l.subs {
-		sub.setLevel(newLevel)
+		sub.inherit(level, stackLevel)
 	}
-	l.level = newLevel
 }
 
 func (l *named) Name() string    { return l.name }
 func (l *named) Names() []string { return l.names }
 
-func (l *named) Level() Level { return l.level }
+func (l *named) Level() Level { return Level(atomic.LoadInt32(&l.level)) }
+
+// StackLevel returns the level at or above which records get a stack trace, stackOff when off
+func (l *named) StackLevel() Level { return Level(atomic.LoadInt32(&l.stackLevel)) }
 
 func (l *named) WithLevel(newLevel Level) Logger {
 	return logger{
@@ -84,6 +287,7 @@ func (l *named) With(name string, value interface{}) Logger {
 		named: l,
 		level: LevelDefault,
 		data:  map[string]interface{}{name: value},
+		keys:  []string{name},
 	}
 }
 
@@ -94,10 +298,13 @@ type INamed interface {
 	All() map[string]INamed
 }
 
+// All returns the sub names, keyed by the next segment of the name
 func (l *named) All() map[string]INamed {
+	treeMutex.RLock()
+	defer treeMutex.RUnlock()
 	all := map[string]INamed{}
-	for _, s := range l.subs {
-		all[s.name] = s
+	for segment, s := range l.subs {
+		all[segment] = s
 	}
 	return all
 }
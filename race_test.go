@@ -0,0 +1,114 @@
+package logger_test
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-msvc/logger"
+)
+
+// run with go test -race to check that levels and writers
+// can be changed while many goroutines are logging
+func TestConcurrentChanges(t *testing.T) {
+	w1 := &countWriter{}
+	w2 := &countWriter{}
+	parent := logger.Named("example.com/race")
+	parent.SetWriter(w1)
+	defer parent.SetWriter(nil)
+	defer parent.SetLevel(logger.LevelDefault)
+
+	stop := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			l := logger.Named(fmt.Sprintf("example.com/race/%d", i%3)).With("i", i)
+			for n := 0; ; n++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				l.Debugf("debug %d", n)
+				l.Infof("info %d", n)
+				l.New(fmt.Sprintf("sub%d", n%5)).Error("error")
+				runtime.Gosched()
+			}
+		}(i)
+	}
+
+	levels := []logger.Level{logger.LevelError, logger.LevelInfo, logger.LevelDebug, logger.LevelDefault}
+	for n := 0; n < 200; n++ {
+		parent.SetLevel(levels[n%len(levels)])
+		logger.Named("example.com/race/1").SetLevelFor(logger.LevelTrace, time.Millisecond)
+		if n%2 == 0 {
+			parent.SetWriter(w1)
+		} else {
+			parent.SetWriter(w2)
+		}
+		logger.Escalations()
+		for _, sub := range logger.All()["example.com"].All()["race"].All() {
+			sub.Level()
+			sub.All()
+		}
+		runtime.Gosched()
+	}
+	close(stop)
+	wg.Wait()
+
+	if atomic.LoadInt64(&w1.count) == 0 || atomic.LoadInt64(&w2.count) == 0 {
+		t.Fatalf("not written to both writers: %d, %d", w1.count, w2.count)
+	}
+}
+
+// run with go test -race to check rules can change while writing
+func TestCodeWriterConcurrent(t *testing.T) {
+	w := &countWriter{}
+	cw := logger.NewCodeWriter(w, logger.LevelError)
+	l := logger.Named("example.com/codewriter").WithLevel(logger.LevelDebug)
+	l.SetWriter(cw)
+	defer l.SetWriter(nil)
+
+	file := "github.com/go-msvc/logger_test/race_test.go"
+	stop := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				l.Debugf("debug")
+				runtime.Gosched()
+			}
+		}()
+	}
+	for n := 0; n < 200; n++ {
+		cw.SetFileLevel(file, logger.LevelDebug)
+		cw.SetFileLineLevel(file, n, logger.LevelInfo)
+		cw.Rules()
+		if n%10 == 0 {
+			cw.ClearRules()
+		}
+		runtime.Gosched()
+	}
+	close(stop)
+	wg.Wait()
+}
+
+type countWriter struct {
+	count int64
+}
+
+func (w *countWriter) Write(r logger.Record) {
+	atomic.AddInt64(&w.count, 1)
+}
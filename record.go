@@ -9,4 +9,13 @@ type Record struct {
 	Level     Level
 	Message   string
 	Data      map[string]interface{}
+	Error     error     //set with WithError() or Errorw(), see GetErrorInfo() for how writers describe it
+	Keys      []string  //names in Data in the order they were added, nil if not known
+	Fields    []Field   //from WithFields() then the log call, written after Data, the last of a name replaces data and earlier fields of that name
+	Header    Header    //request and trace metadata
+	Resource  *Resource //process that wrote the record, see SetResource(), never nil in records of a logger
+
+	//stacks are only captured for records at or above the stack level, see SetGlobalStackLevel()
+	Stack      []Caller //goroutine that logged, starting at Caller
+	ErrorStack []Caller //stack of Error or else of the first error in Data that has one, see ErrorStack()
 }
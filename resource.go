@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"sync/atomic"
+)
+
+// Resource identifies the process that writes the logs
+// it is set once at startup with SetResource() and every record refers to it
+type Resource struct {
+	Service    string `json:"service,omitempty"`     //default is the name of the program
+	Version    string `json:"version,omitempty"`     //default is the main module version
+	InstanceID string `json:"instance_id,omitempty"` //default is random for each process
+	Hostname   string `json:"hostname,omitempty"`
+	PID        int    `json:"pid,omitempty"`
+
+	//from the build info of the program
+	ModulePath    string `json:"module_path,omitempty"`
+	ModuleVersion string `json:"module_version,omitempty"`
+	Revision      string `json:"vcs_revision,omitempty"`
+	Modified      bool   `json:"vcs_modified,omitempty"` //built with uncommitted changes
+}
+
+// resource is set when declared rather than in init()
+// so that it exists before any init() in this package logs
+var resource = newResource()
+
+func newResource() *atomic.Value {
+	r := DetectResource()
+	v := &atomic.Value{}
+	v.Store(&r)
+	return v
+}
+
+// DetectResource returns the resource of this process without the settings of SetResource()
+func DetectResource() Resource {
+	r := Resource{
+		Service: filepath.Base(os.Args[0]),
+		PID:     os.Getpid(),
+	}
+	r.Hostname, _ = os.Hostname()
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err == nil {
+		r.InstanceID = hex.EncodeToString(id)
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		r.ModulePath = info.Main.Path
+		if info.Main.Version != "(devel)" {
+			r.ModuleVersion = info.Main.Version
+		}
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				r.Revision = s.Value
+			case "vcs.modified":
+				r.Modified = s.Value == "true"
+			}
+		}
+	}
+	r.Version = r.ModuleVersion
+	return r
+} //DetectResource()
+
+// SetResource sets the resource written with all records from now on
+// fields that are not set are detected like DetectResource(), e.g.
+// SetResource(Resource{Service: "orders", Version: "1.2.3"}) at the start of main()
+func SetResource(r Resource) {
+	d := DetectResource()
+	if r.InstanceID == "" {
+		//keep the instance id of the process
+		d.InstanceID = GetResource().InstanceID
+	}
+	set := func(s *string, v string) {
+		if *s == "" {
+			*s = v
+		}
+	}
+	set(&r.Service, d.Service)
+	set(&r.Version, d.Version)
+	set(&r.InstanceID, d.InstanceID)
+	set(&r.Hostname, d.Hostname)
+	set(&r.ModulePath, d.ModulePath)
+	set(&r.ModuleVersion, d.ModuleVersion)
+	if r.Revision == "" {
+		r.Revision = d.Revision
+		r.Modified = d.Modified
+	}
+	if r.PID == 0 {
+		r.PID = d.PID
+	}
+	resource.Store(&r)
+} //SetResource()
+
+// GetResource returns the resource written with records
+func GetResource() Resource {
+	return *resource.Load().(*Resource)
+}
+
+// fields lists the fields that are set, named like the json tags
+func (r *Resource) fields() []headerField {
+	if r == nil {
+		return nil
+	}
+	list := make([]headerField, 0, 9)
+	add := func(name, value string) {
+		if value != "" {
+			list = append(list, headerField{name: name, value: value})
+		}
+	}
+	add("service", r.Service)
+	add("version", r.Version)
+	add("instance_id", r.InstanceID)
+	add("hostname", r.Hostname)
+	if r.PID != 0 {
+		add("pid", strconv.Itoa(r.PID))
+	}
+	add("module_path", r.ModulePath)
+	add("module_version", r.ModuleVersion)
+	add("vcs_revision", r.Revision)
+	if r.Modified {
+		add("vcs_modified", "true")
+	}
+	return list
+}
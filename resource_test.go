@@ -0,0 +1,66 @@
+package logger_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-msvc/logger"
+)
+
+func TestResource(t *testing.T) {
+	original := logger.GetResource()
+	defer logger.SetResource(original)
+	if original.PID != os.Getpid() || original.InstanceID == "" || original.Service == "" {
+		t.Fatalf("not detected: %+v", original)
+	}
+
+	logger.SetResource(logger.Resource{Service: "orders", Version: "1.2.3"})
+	r := logger.GetResource()
+	if r.Service != "orders" || r.Version != "1.2.3" || r.InstanceID != original.InstanceID || r.PID != os.Getpid() || r.Hostname != original.Hostname {
+		t.Fatalf("resource: %+v", r)
+	}
+
+	w := &testWriter{records: []logger.Record{}}
+	l := logger.Named("example.com/resource")
+	l.SetWriter(w)
+	defer l.SetWriter(nil)
+	l.Error("one")
+	if len(w.records) != 1 || w.records[0].Resource == nil || *w.records[0].Resource != r {
+		t.Fatalf("record resource: %+v", w.records)
+	}
+
+	logger.SetResource(logger.Resource{Service: "orders", Version: "1.2.3", InstanceID: "i1", Hostname: "h1", PID: 5, ModulePath: "example.com/orders"})
+	res := logger.GetResource()
+	res.ModuleVersion = ""
+	res.Revision = "abc"
+	rec := logger.Record{Message: "hi", Resource: &res}
+
+	buf := bytes.NewBuffer(nil)
+	logger.NewJSONEncoder(logger.JSONConfig{TimeKey: "-", LevelKey: "-", NameKey: "-", ResourceKey: "resource"}).Encode(buf, rec)
+	expected := `{"msg":"hi","resource":{"service":"orders","version":"1.2.3","instance_id":"i1","hostname":"h1","pid":5,"module_path":"example.com/orders","vcs_revision":"abc"}}` + "\n"
+	if buf.String() != expected {
+		t.Fatalf("json: %s", buf.String())
+	}
+
+	buf.Reset()
+	logger.NewLogfmtEncoder(logger.LogfmtConfig{Resource: true}).Encode(buf, rec)
+	if !strings.HasSuffix(buf.String(), ` msg=hi resource.service=orders resource.version=1.2.3 resource.instance_id=i1 resource.hostname=h1 resource.pid=5 resource.module_path=example.com/orders resource.vcs_revision=abc`+"\n") {
+		t.Fatalf("logfmt: %s", buf.String())
+	}
+
+	buf.Reset()
+	text, _ := logger.NewTextEncoder("{{.Resource.Service}}/{{.Resource.PID}}")
+	text.Encode(buf, rec)
+	if buf.String() != "orders/5\n" {
+		t.Fatalf("text: %s", buf.String())
+	}
+
+	//records made without a logger may not have a resource
+	buf.Reset()
+	text.Encode(buf, logger.Record{Message: "hi"})
+	if buf.String() != "/0\n" {
+		t.Fatalf("text without resource: %s", buf.String())
+	}
+}
@@ -0,0 +1,189 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ISamplingWriter limits the number of similar records written in each interval
+type ISamplingWriter interface {
+	IWriter
+	IFlusher
+	ICloser //Close writes the summary of the current interval, stops then closes the writer
+}
+
+// SamplingConfig controls NewSamplingWriter()
+type SamplingConfig struct {
+	Interval   time.Duration //default 1s
+	First      int           //write the first records of each call site in each interval, default 10
+	Thereafter int           //then write every Mth record, 0 to drop all until the interval ends
+}
+
+// NewSamplingWriter makes a writer that caps the volume of records logged repeatedly from the same place
+// records are similar when they have the same logger, level and call site (or message when there is no caller)
+// in each interval the first records are written, then only every Mth, and when the interval ends
+// a summary record is written for each call site with the number of records that were suppressed
+func NewSamplingWriter(w IWriter, c SamplingConfig) ISamplingWriter {
+	if c.Interval <= 0 {
+		c.Interval = time.Second
+	}
+	if c.First <= 0 {
+		c.First = 10
+	}
+	sw := &samplingWriter{
+		writer: w,
+		config: c,
+		counts: map[sampleKey]*sampleCount{},
+	}
+	sw.stop = sampleTicker(c.Interval, sw.endInterval)
+	return sw
+}
+
+type samplingWriter struct {
+	writer IWriter
+	config SamplingConfig
+
+	mutex  sync.Mutex
+	counts map[sampleKey]*sampleCount //counts in the current interval
+	closed bool
+
+	stop func() //stops the ticker that ends each interval
+}
+
+// sampleKey identifies similar records
+type sampleKey struct {
+	name    string
+	level   Level
+	file    string
+	line    int
+	message string //only when there is no caller
+}
+
+type sampleCount struct {
+	count      int
+	suppressed int
+	last       Record //last suppressed record, used for the summary
+}
+
+func (sw *samplingWriter) Write(r Record) {
+	key := sampleKey{name: recordName(r), level: r.Level}
+	if r.Caller != nil {
+		key.file = r.Caller.PackageFile()
+		key.line = r.Caller.Line()
+	} else {
+		key.message = r.Message
+	}
+
+	sw.mutex.Lock()
+	if sw.closed {
+		sw.mutex.Unlock()
+		return
+	}
+	c, ok := sw.counts[key]
+	if !ok {
+		c = &sampleCount{}
+		sw.counts[key] = c
+	}
+	c.count++
+	n := c.count - sw.config.First
+	write := n <= 0 || (sw.config.Thereafter > 0 && n%sw.config.Thereafter == 0)
+	if !write {
+		c.suppressed++
+		c.last = r
+	}
+	sw.mutex.Unlock()
+
+	if write {
+		sw.writer.Write(r)
+	}
+} //samplingWriter.Write()
+
+// sampleTicker calls f every d in a background goroutine until stop() is called
+// stop() returns when a call to f in progress returned
+// tests replace it to end intervals without waiting
+var sampleTicker = func(d time.Duration, f func()) (stop func()) {
+	ticker := time.NewTicker(d)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-ticker.C:
+				f()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+		<-stopped
+	}
+}
+
+// endInterval writes a summary for each call site with suppressed records and starts counting again
+func (sw *samplingWriter) endInterval() {
+	sw.mutex.Lock()
+	counts := sw.counts
+	sw.counts = map[sampleKey]*sampleCount{}
+	sw.mutex.Unlock()
+
+	for _, c := range counts {
+		if c.suppressed == 0 {
+			continue
+		}
+		r := c.last
+		r.Timestamp = time.Now()
+		r.Message = fmt.Sprintf("suppressed %d similar records: %s", c.suppressed, r.Message)
+		r.Data = map[string]interface{}{"suppressed": c.suppressed}
+		r.Keys = []string{"suppressed"}
+		r.Fields = nil
+		sw.writer.Write(r)
+	}
+}
+
+func (sw *samplingWriter) Flush(ctx context.Context) error {
+	if f, ok := sw.writer.(IFlusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+func (sw *samplingWriter) Close() error {
+	if !sw.stopSampling() {
+		return nil
+	}
+	if c, ok := sw.writer.(ICloser); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (sw *samplingWriter) closeOwn() error {
+	sw.stopSampling()
+	return nil
+}
+
+// stopSampling stops and writes the summary of the last interval
+// it returns false when already stopped
+func (sw *samplingWriter) stopSampling() bool {
+	sw.mutex.Lock()
+	if sw.closed {
+		sw.mutex.Unlock()
+		return false
+	}
+	sw.closed = true
+	sw.mutex.Unlock()
+
+	sw.stop()
+	sw.endInterval()
+	return true
+}
+
+func (sw *samplingWriter) flushOwn(ctx context.Context) error { return nil }
+
+func (sw *samplingWriter) Writers() []IWriter { return []IWriter{sw.writer} }
@@ -0,0 +1,83 @@
+package logger_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-msvc/logger"
+)
+
+func TestSamplingWriter(t *testing.T) {
+	w := &lifecycleWriter{}
+	sw := logger.NewSamplingWriter(w, logger.SamplingConfig{Interval: time.Hour, First: 3, Thereafter: 5})
+	l := logger.Named("example.com/sampling")
+	l.SetWriter(sw)
+	defer l.SetWriter(nil)
+
+	for i := 0; i < 20; i++ {
+		l.Error(fmt.Sprintf("loop %d", i), logger.Int("i", i)) //same call site every time
+	}
+	l.Errorf("other")
+	l.Error("plain")
+	//first 3, then 8th, 13th and 18th
+	messages := []string{"loop 0", "loop 1", "loop 2", "loop 7", "loop 12", "loop 17", "other", "plain"}
+	if len(w.records) != len(messages) {
+		t.Fatalf("%d records", len(w.records))
+	}
+	for i, m := range messages {
+		if w.records[i].Message != m {
+			t.Fatalf("record %d: %q, expected %q", i, w.records[i].Message, m)
+		}
+	}
+
+	//close writes the summary
+	sw.Close()
+	if w.closed != 1 || len(w.records) != len(messages)+1 {
+		t.Fatalf("closed=%d records=%d", w.closed, len(w.records))
+	}
+	summary := w.records[len(messages)]
+	if summary.Message != "suppressed 14 similar records: loop 19" || summary.Data["suppressed"] != 14 || len(summary.Fields) != 0 ||
+		summary.Level != logger.LevelError || summary.Caller.Line() != w.records[0].Caller.Line() {
+		t.Fatalf("summary: %+v", summary)
+	}
+	sw.Write(logger.Record{Message: "after close"})
+	if len(w.records) != len(messages)+1 {
+		t.Fatalf("written after close")
+	}
+}
+
+func TestSamplingWriterInterval(t *testing.T) {
+	tick, restore := logger.FakeSamplingTicker()
+	defer restore()
+	w := &testWriter{records: []logger.Record{}}
+	sw := logger.NewSamplingWriter(w, logger.SamplingConfig{Interval: time.Hour, First: 1})
+	defer sw.Close()
+	write := func() {
+		for i := 0; i < 5; i++ {
+			sw.Write(logger.Record{Level: logger.LevelInfo, Message: "same"})
+		}
+	}
+	write()
+	if len(w.records) != 1 {
+		t.Fatalf("%d records", len(w.records))
+	}
+	//summary at the end of the interval, then counting starts again
+	tick()
+	if len(w.records) != 2 {
+		t.Fatalf("%d records after the interval", len(w.records))
+	}
+	if summary := w.records[1]; summary.Message != "suppressed 4 similar records: same" {
+		t.Fatalf("summary: %q", summary.Message)
+	}
+	write()
+	if len(w.records) != 3 {
+		t.Fatalf("%d records in the next interval", len(w.records))
+	}
+	//no summary for an interval without suppressed records
+	tick()
+	tick()
+	if len(w.records) != 4 || w.records[3].Message != "suppressed 4 similar records: same" {
+		t.Fatalf("records: %+v", w.records)
+	}
+}
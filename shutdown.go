@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"context"
+	"strings"
+)
+
+// Flush flushes every writer used by any named logger, including the writers inside
+// wrappers such as NewMultiWriter(), each one once
+// it returns when all writers were flushed or ctx is done
+func Flush(ctx context.Context) error {
+	treeMutex.RLock()
+	set := writerSet{}
+	top.writers(&set)
+	treeMutex.RUnlock()
+
+	return flushWriters(ctx, set.ordered())
+}
+
+// Shutdown flushes then closes every writer used by any named logger, including the writers
+// inside wrappers such as NewMultiWriter(), each one once
+// then sets the default writer on all loggers so that later logs are not lost
+// call it before the program exits
+func Shutdown(ctx context.Context) error {
+	treeMutex.Lock()
+	set := writerSet{}
+	top.writers(&set)
+	top.resetWriters()
+	treeMutex.Unlock()
+
+	return closeWriters(ctx, set.ordered())
+} //Shutdown()
+
+// ownLifecycle is implemented by the wrappers in this package
+// their Flush() and Close() are passed on to the writers they wrap, while flushOwn() and closeOwn()
+// only deal with the wrapper itself, e.g. write the queue of an async writer, because
+// Flush() and Shutdown() flush and close the wrapped writers themselves
+type ownLifecycle interface {
+	flushOwn(ctx context.Context) error
+	closeOwn() error
+}
+
+// flushWriters flushes writers in the order of writerSet.ordered()
+func flushWriters(ctx context.Context, writers []IWriter) error {
+	errs := errorList{}
+	for _, w := range writers {
+		var err error
+		if o, ok := w.(ownLifecycle); ok {
+			err = o.flushOwn(ctx)
+		} else if f, ok := w.(IFlusher); ok {
+			err = f.Flush(ctx)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// closeWriters flushes then closes writers in the order of writerSet.ordered()
+func closeWriters(ctx context.Context, writers []IWriter) error {
+	errs := errorList{}
+	if err := flushWriters(ctx, writers); err != nil {
+		errs = append(errs, err)
+	}
+	for _, w := range writers {
+		var err error
+		if o, ok := w.(ownLifecycle); ok {
+			err = o.closeOwn()
+		} else if c, ok := w.(ICloser); ok {
+			err = c.Close()
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+} //closeWriters()
+
+// writerSet is a list of distinct writers and the writers inside them, see IWrapper
+// writers that can be compared are identified with ==, e.g. by their pointer,
+// the others cannot be told apart so each one is in the list
+type writerSet struct {
+	list []IWriter //each writer after all writers it wraps
+	seen map[IWriter]bool
+}
+
+// add adds w and the writers it wraps
+func (set *writerSet) add(w IWriter) {
+	if w == nil {
+		return
+	}
+	if comparable(w) {
+		if set.seen[w] {
+			return
+		}
+		if set.seen == nil {
+			set.seen = map[IWriter]bool{}
+		}
+		set.seen[w] = true
+	}
+	if wrapper, ok := w.(IWrapper); ok {
+		for _, inner := range wrapper.Writers() {
+			set.add(inner)
+		}
+	}
+	set.list = append(set.list, w)
+} //writerSet.add()
+
+// has is true when w is in the set, and false when w cannot be compared
+func (set *writerSet) has(w IWriter) bool {
+	return comparable(w) && set.seen[w]
+}
+
+// ordered returns the writers with each wrapper before the writers it wraps
+// so that e.g. an async writer writes its queue before the writer inside it is closed
+func (set *writerSet) ordered() []IWriter {
+	list := make([]IWriter, len(set.list))
+	for i, w := range set.list {
+		list[len(list)-1-i] = w
+	}
+	return list
+}
+
+// comparable is false when == would panic on w, e.g. for a struct writer with a slice field
+func comparable(w IWriter) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return w == w
+}
+
+// errorList combines the errors from all writers
+type errorList []error
+
+func (errs errorList) Error() string {
+	s := make([]string, len(errs))
+	for i, err := range errs {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "; ")
+}
@@ -0,0 +1,142 @@
+package logger_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-msvc/logger"
+)
+
+type lifecycleWriter struct {
+	testWriter
+	flushed int
+	closed  int
+}
+
+func (w *lifecycleWriter) Flush(ctx context.Context) error {
+	w.flushed++
+	return nil
+}
+
+func (w *lifecycleWriter) Close() error {
+	w.closed++
+	return nil
+}
+
+func TestFlushAndShutdown(t *testing.T) {
+	w1 := &lifecycleWriter{}
+	w2 := &lifecycleWriter{}
+	a := logger.Named("example.com/lifecycle/a")
+	b := logger.Named("example.com/lifecycle/b")
+	a.SetWriter(w1)
+	b.SetWriter(w1)
+	logger.Named("example.com/lifecycle/a/sub").SetWriter(w2)
+
+	if err := logger.Flush(context.Background()); err != nil || w1.flushed != 1 || w2.flushed != 1 {
+		t.Fatalf("flush: %v w1=%d w2=%d", err, w1.flushed, w2.flushed)
+	}
+
+	//a replaced writer is flushed and closed when no logger uses it any more
+	a.SetWriter(w2)
+	if w1.closed != 0 {
+		t.Fatalf("closed while b uses it")
+	}
+	b.SetWriter(w2)
+	if w1.flushed != 2 || w1.closed != 1 || w2.closed != 0 {
+		t.Fatalf("replaced: w1 flushed=%d closed=%d w2 closed=%d", w1.flushed, w1.closed, w2.closed)
+	}
+
+	//shutdown flushes and closes each writer once then uses the default writer
+	if err := logger.Shutdown(context.Background()); err != nil || w2.flushed != 2 || w2.closed != 1 {
+		t.Fatalf("shutdown: %v flushed=%d closed=%d", err, w2.flushed, w2.closed)
+	}
+	a.Error("after shutdown")
+	if len(w2.records) != 0 {
+		t.Fatalf("written after shutdown")
+	}
+}
+
+func TestAsyncWriterShutdown(t *testing.T) {
+	w := &lifecycleWriter{}
+	l := logger.Named("example.com/lifecycle/async")
+	l.SetWriter(logger.NewAsyncWriter(w, logger.AsyncConfig{}))
+	for i := 0; i < 10; i++ {
+		l.Errorf("%d", i)
+	}
+	if err := logger.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if len(w.records) != 10 || w.flushed != 1 || w.closed != 1 {
+		t.Fatalf("records=%d flushed=%d closed=%d", len(w.records), w.flushed, w.closed)
+	}
+}
+
+// valueWriter is not a pointer, and counts its calls in a shared counter
+// it cannot be compared when w cannot be compared, e.g. a writerFunc
+type valueWriter struct {
+	w     logger.IWriter
+	count *lifecycleWriter
+}
+
+func (w valueWriter) Write(r logger.Record)           { w.w.Write(r) }
+func (w valueWriter) Flush(ctx context.Context) error { return w.count.Flush(ctx) }
+func (w valueWriter) Close() error                    { return w.count.Close() }
+
+func TestShutdownSharedWriter(t *testing.T) {
+	file := &lifecycleWriter{}
+	async := logger.NewAsyncWriter(file, logger.AsyncConfig{})
+	a := logger.Named("example.com/lifecycle/shared/a")
+	b := logger.Named("example.com/lifecycle/shared/b")
+	c := logger.Named("example.com/lifecycle/shared/c")
+	a.SetWriter(async)
+	b.SetWriter(file)
+	c.SetWriter(logger.NewMultiWriter(logger.Branch(file, logger.LevelDefault), logger.Branch(async, logger.LevelDefault)))
+
+	//replacing a wrapper does not close the writers inside it that others still use
+	c.SetWriter(nil)
+	a.SetWriter(nil)
+	b.Error("still open")
+	if file.closed != 0 || len(file.records) != 1 {
+		t.Fatalf("shared writer closed=%d records=%d", file.closed, len(file.records))
+	}
+	//the async writer was closed, as nothing uses it any more
+	async.Write(logger.Record{Message: "dropped"})
+	if async.Dropped() != 1 {
+		t.Fatalf("replaced async writer not closed")
+	}
+
+	//writers inside wrappers are flushed and closed once, also when used directly
+	a.SetWriter(logger.NewSamplingWriter(logger.NewCodeWriter(file, logger.LevelError), logger.SamplingConfig{}))
+	c.SetWriter(logger.NewMultiWriter(logger.Branch(file, logger.LevelDefault)))
+
+	//values that can be compared are flushed once, the others each time they are used
+	same := &lifecycleWriter{}
+	logger.Named("example.com/lifecycle/shared/d").SetWriter(valueWriter{w: same, count: same})
+	logger.Named("example.com/lifecycle/shared/e").SetWriter(valueWriter{w: same, count: same})
+	other := &lifecycleWriter{}
+	logger.Named("example.com/lifecycle/shared/f").SetWriter(valueWriter{w: writerFunc(func(logger.Record) {}), count: other})
+	logger.Named("example.com/lifecycle/shared/g").SetWriter(valueWriter{w: writerFunc(func(logger.Record) {}), count: other})
+
+	file.flushed = 0
+	if err := logger.Flush(context.Background()); err != nil || file.flushed != 1 || same.flushed != 1 || other.flushed != 2 {
+		t.Fatalf("flush: %v file=%d same=%d other=%d", err, file.flushed, same.flushed, other.flushed)
+	}
+	if err := logger.Shutdown(context.Background()); err != nil || file.closed != 1 || same.closed != 1 || other.closed != 2 {
+		t.Fatalf("shutdown: %v file=%d same=%d other=%d", err, file.closed, same.closed, other.closed)
+	}
+}
+
+func TestShutdownSharedFile(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "x.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := logger.NewStreamWriter(f, logger.NewLogfmtEncoder(logger.LogfmtConfig{}))
+	logger.Named("example.com/lifecycle/file/a").SetWriter(w)
+	logger.Named("example.com/lifecycle/file/b").SetWriter(logger.NewMultiWriter(logger.Branch(w, logger.LevelDefault)))
+	if err := logger.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// maxStackDepth limits the number of frames captured in a stack
+const maxStackDepth = 64
+
+// GetStack returns the stack of the calling goroutine
+// skip works like GetCaller(), so GetStack(skip)[0] is the same frame as GetCaller(skip)
+func GetStack(skip int) []Caller {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip+1, pcs)
+	return stackCallers(pcs[:n])
+}
+
+// stackCallers makes a Caller for each program counter
+func stackCallers(pcs []uintptr) []Caller {
+	if len(pcs) == 0 {
+		return nil
+	}
+	stack := make([]Caller, 0, len(pcs))
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "runtime.goexit" {
+			stack = append(stack, caller{file: frame.File, line: frame.Line, pkgDotFunc: frame.Function})
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// ErrorStack returns the stack recorded in err or the errors it wraps, or nil if none has a stack
+// the innermost stack is used, which is where the error was made
+// an error has a stack when it has a method StackTrace() that returns a slice of program counters,
+// e.g. errors made with github.com/pkg/errors, or a method Callers() []uintptr
+func ErrorStack(err error) []Caller {
+	var pcs []uintptr
+	for ; err != nil; err = errors.Unwrap(err) {
+		if s := errorPCs(err); s != nil {
+			pcs = s
+		}
+	}
+	return stackCallers(pcs)
+}
+
+func errorPCs(err error) []uintptr {
+	if c, ok := err.(interface{ Callers() []uintptr }); ok {
+		return c.Callers()
+	}
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	t := m.Type().Out(0)
+	if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	s := m.Call(nil)[0]
+	pcs := make([]uintptr, s.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(s.Index(i).Uint())
+	}
+	return pcs
+}
+
+// dataErrorStack returns the stack of the first error in data or the fields that has one
+func dataErrorStack(r Record) []Caller {
+	for _, n := range dataNames(r, false) {
+		if err, ok := r.Data[n].(error); ok {
+			if stack := ErrorStack(err); stack != nil {
+				return stack
+			}
+		}
+	}
+	for _, f := range r.Fields {
+		if err, ok := f.any.(error); ok {
+			if stack := ErrorStack(err); stack != nil {
+				return stack
+			}
+		}
+	}
+	return nil
+}
+
+// frameString formats a stack frame as "<package>.<function> <file>:<line>"
+func frameString(c Caller) string {
+	return c.Package() + "." + c.Function() + " " + c.File() + ":" + strconv.Itoa(c.Line())
+}
+
+// stackString formats the frames separated by sep
+func stackString(stack []Caller, sep string) string {
+	s := make([]string, len(stack))
+	for i, c := range stack {
+		s[i] = frameString(c)
+	}
+	return strings.Join(s, sep)
+}
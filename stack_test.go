@@ -0,0 +1,106 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/go-msvc/logger"
+)
+
+// stackError records a stack like errors made with github.com/pkg/errors
+type stackError struct {
+	pcs []uintptr
+}
+
+type frame uintptr
+type stackTrace []frame
+
+func (e stackError) Error() string { return "stack error" }
+
+func (e stackError) StackTrace() stackTrace {
+	st := make(stackTrace, len(e.pcs))
+	for i, pc := range e.pcs {
+		st[i] = frame(pc)
+	}
+	return st
+}
+
+func newStackError() error {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	return stackError{pcs: pcs[:n]}
+}
+
+func TestStack(t *testing.T) {
+	w := &testWriter{records: []logger.Record{}}
+	parent := logger.Named("example.com/stack")
+	l := logger.Named("example.com/stack/sub")
+	l.SetWriter(w)
+	defer l.SetWriter(nil)
+
+	l.Error("off by default")
+	parent.SetStackLevel(logger.LevelError)
+	defer parent.SetStackLevel(logger.LevelDefault)
+	l.Error("inherited")
+	l.With("err", fmt.Errorf("wrapped: %w", newStackError())).Errorf("with error")
+	l.SetStackOff()
+	l.Error("turned off")
+
+	if len(w.records) != 4 {
+		t.Fatalf("%d records", len(w.records))
+	}
+	if w.records[0].Stack != nil || w.records[3].Stack != nil {
+		t.Fatalf("stack when off")
+	}
+	for _, r := range w.records[1:3] {
+		if len(r.Stack) < 2 || r.Stack[0].Function() != "TestStack" || r.Stack[0].Line() != r.Caller.Line() || r.Stack[1].Function() != "tRunner" {
+			t.Fatalf("stack: %v", r.Stack)
+		}
+	}
+	if w.records[1].ErrorStack != nil {
+		t.Fatalf("error stack without error")
+	}
+	if es := w.records[2].ErrorStack; len(es) < 2 || es[0].Function() != "TestStack" || es[0].Line() != w.records[2].Caller.Line() {
+		t.Fatalf("error stack: %v", es)
+	}
+}
+
+func TestGlobalStackLevel(t *testing.T) {
+	w := &testWriter{records: []logger.Record{}}
+	l := logger.Named("example.com/stack/global")
+	l.SetWriter(w)
+	defer l.SetWriter(nil)
+	logger.SetGlobalStackLevel(logger.LevelFatal)
+	l.Error("no stack")
+	logger.SetGlobalStackLevel(logger.LevelError)
+	l.Error("stack")
+	logger.SetGlobalStackOff()
+	l.Error("no stack again")
+	if len(w.records) != 3 || w.records[0].Stack != nil || w.records[1].Stack == nil || w.records[2].Stack != nil {
+		t.Fatalf("records: %+v", w.records)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	logger.NewJSONEncoder(logger.JSONConfig{}).Encode(buf, w.records[1])
+	var obj struct {
+		Stack []struct {
+			Function string `json:"function"`
+			Line     int    `json:"line"`
+		} `json:"stack"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &obj); err != nil || len(obj.Stack) != len(w.records[1].Stack) || obj.Stack[0].Function != "TestGlobalStackLevel" {
+		t.Fatalf("json: %v %s", err, buf.String())
+	}
+
+	buf.Reset()
+	text, _ := logger.NewTextEncoder(logger.DefaultTextLayout)
+	text.Encode(buf, w.records[1])
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 1+len(w.records[1].Stack) || !strings.HasPrefix(lines[1], "\tgithub.com/go-msvc/logger_test.TestGlobalStackLevel ") {
+		t.Fatalf("text: %s", buf.String())
+	}
+}
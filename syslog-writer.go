@@ -0,0 +1,497 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// ISyslogWriter sends records to a syslog server
+type ISyslogWriter interface {
+	IWriter
+	ICloser
+}
+
+// SyslogConfig controls NewSyslogWriter()
+type SyslogConfig struct {
+	Network string //"udp", "tcp", "unix" or "unixgram", or "" for the local syslog socket, e.g. /dev/log
+	Address string //e.g. "localhost:514" or a socket path, not used when Network is ""
+
+	Format   SyslogFormat   //default SyslogRFC5424
+	Facility SyslogFacility //default SyslogUser
+	AppName  string         //default is the name of the program
+	Hostname string         //default os.Hostname()
+	SDID     string         //RFC 5424 STRUCTURED-DATA id for Record.Data, default "data@32473"
+	HeaderID string         //RFC 5424 STRUCTURED-DATA id for Record.Header, default "header@32473"
+	Resource bool           //also write Record.Resource, with RFC 5424 as STRUCTURED-DATA "resource@32473"
+
+	Timeout time.Duration //for connecting and for each write on stream connections, default 5s
+}
+
+// SyslogFormat is the message format written by a syslog writer
+type SyslogFormat int
+
+const (
+	SyslogRFC5424 SyslogFormat = iota //<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD] MSG
+	SyslogRFC3164                     //<PRI>Mmm dd hh:mm:ss HOSTNAME APP-NAME[PID]: MSG
+)
+
+// SyslogFacility is the syslog facility code
+// the kernel facility (0) is not available, so that the zero value means SyslogUser
+type SyslogFacility int
+
+const (
+	SyslogUser SyslogFacility = iota + 1
+	SyslogMail
+	SyslogDaemon
+	SyslogAuth
+	SyslogSyslog
+	SyslogLpr
+	SyslogNews
+	SyslogUucp
+	SyslogCron
+	SyslogAuthPriv
+	SyslogFtp
+)
+
+const (
+	SyslogLocal0 SyslogFacility = iota + 16
+	SyslogLocal1
+	SyslogLocal2
+	SyslogLocal3
+	SyslogLocal4
+	SyslogLocal5
+	SyslogLocal6
+	SyslogLocal7
+)
+
+// syslogSeverity maps Level to syslog severity (0=Emergency..7=Debug)
+func syslogSeverity(l Level) int {
+	switch l {
+	case LevelPanic:
+		return 1 //alert
+	case LevelFatal:
+		return 2 //critical
+	case LevelError:
+		return 3
+	case LevelWarn:
+		return 4
+	case LevelInfo:
+		return 6
+	default:
+		return 7 //debug and trace
+	}
+}
+
+// localSyslogSockets are tried in order when Network is ""
+var localSyslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// NewSyslogWriter connects to the syslog server and returns a writer that is safe
+// to use from many goroutines. When sending fails, the writer reports the error on stderr,
+// drops the record and connects again in the background, dropping records until connected.
+//
+// RFC 5424 messages have the logger name as MSGID and Record.Header and Record.Data as STRUCTURED-DATA,
+// RFC 3164 messages have the logger name, header and data as key=value after the message.
+// TCP uses octet-counting framing and unix stream sockets end each message with a newline.
+func NewSyslogWriter(c SyslogConfig) (ISyslogWriter, error) {
+	if c.AppName == "" {
+		c.AppName = filepath.Base(os.Args[0])
+	}
+	if c.Hostname == "" {
+		c.Hostname, _ = os.Hostname()
+	}
+	if c.SDID == "" {
+		c.SDID = "data@32473"
+	}
+	if c.HeaderID == "" {
+		c.HeaderID = "header@32473"
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 5 * time.Second
+	}
+	if c.Facility == 0 {
+		c.Facility = SyslogUser
+	}
+	if c.Facility < 0 || c.Facility > SyslogLocal7 {
+		return nil, fmt.Errorf("invalid syslog facility %d", c.Facility)
+	}
+	sw := &syslogWriter{
+		config: c,
+		pid:    strconv.Itoa(os.Getpid()),
+		done:   make(chan struct{}),
+	}
+	conn, err := sw.dial()
+	if err != nil {
+		return nil, err
+	}
+	sw.setConn(conn)
+	return sw, nil
+} //NewSyslogWriter()
+
+type syslogWriter struct {
+	config SyslogConfig
+	pid    string
+	done   chan struct{} //closed by Close() to stop reconnecting
+
+	sync.Mutex
+	conn         net.Conn //nil while disconnected
+	reconnecting bool     //true while reconnect() is running
+	stream       bool     //true when messages need framing: octet-counting on tcp, newline on unix sockets
+	octets       bool     //true for octet-counting framing
+	buf          bytes.Buffer
+	lastErr      string //last error written to stderr, to not repeat it for every record
+	closed       bool
+}
+
+// syslog reconnect attempts are retried with an increasing delay between these limits
+const (
+	syslogMinRetry = 100 * time.Millisecond
+	syslogMaxRetry = 10 * time.Second
+)
+
+// dial connects to the server
+// it does not use sw's connection, so it is called without sw locked
+func (sw *syslogWriter) dial() (net.Conn, error) {
+	if sw.config.Network != "" {
+		return net.DialTimeout(sw.config.Network, sw.config.Address, sw.config.Timeout)
+	}
+	for _, path := range localSyslogSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			if conn, err := net.DialTimeout(network, path, sw.config.Timeout); err == nil {
+				return conn, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("local syslog socket not found")
+} //syslogWriter.dial()
+
+// setConn starts using a connection made with dial()
+// must be called with sw locked, or before sw is used
+func (sw *syslogWriter) setConn(conn net.Conn) {
+	sw.conn = conn
+	network := conn.RemoteAddr().Network()
+	switch network {
+	case "udp", "udp4", "udp6", "unixgram":
+		sw.stream = false
+	default:
+		sw.stream = true
+	}
+	sw.octets = strings.HasPrefix(network, "tcp")
+}
+
+// reconnect dials in the background until connected or closed
+// records written in the meantime are dropped, so that logging never waits for the server
+func (sw *syslogWriter) reconnect() {
+	delay := syslogMinRetry
+	for {
+		conn, err := sw.dial()
+		if err == nil {
+			sw.Lock()
+			sw.reconnecting = false
+			if sw.closed {
+				sw.Unlock()
+				conn.Close()
+				return
+			}
+			sw.setConn(conn)
+			sw.Unlock()
+			return
+		}
+		select {
+		case <-sw.done:
+			sw.Lock()
+			sw.reconnecting = false
+			sw.Unlock()
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > syslogMaxRetry {
+			delay = syslogMaxRetry
+		}
+	}
+} //syslogWriter.reconnect()
+
+func (sw *syslogWriter) Write(r Record) {
+	sw.Lock()
+	defer sw.Unlock()
+	if sw.closed {
+		return
+	}
+	if sw.conn == nil {
+		sw.report(fmt.Errorf("not connected, dropping records"))
+		return
+	}
+	msg := sw.format(r)
+	sw.buf.Reset()
+	if sw.octets {
+		//octet-counting (RFC 6587)
+		sw.buf.WriteString(strconv.Itoa(len(msg)))
+		sw.buf.WriteByte(' ')
+		sw.buf.Write(msg)
+	} else {
+		sw.buf.Write(msg)
+		if sw.stream {
+			sw.buf.WriteByte('\n')
+		}
+	}
+
+	if err := sw.send(); err != nil {
+		//drop the connection and connect again in the background
+		sw.conn.Close()
+		sw.conn = nil
+		if !sw.reconnecting {
+			sw.reconnecting = true
+			go sw.reconnect()
+		}
+		sw.report(err)
+		return
+	}
+	sw.lastErr = ""
+} //syslogWriter.Write()
+
+// report writes an error to stderr unless it is the same as the last error
+// must be called with sw locked
+func (sw *syslogWriter) report(err error) {
+	if err.Error() != sw.lastErr {
+		sw.lastErr = err.Error()
+		fmt.Fprintf(os.Stderr, "logger: syslog writer: %v\n", err)
+	}
+}
+
+func (sw *syslogWriter) send() error {
+	if sw.stream {
+		sw.conn.SetWriteDeadline(time.Now().Add(sw.config.Timeout))
+	}
+	_, err := sw.conn.Write(sw.buf.Bytes())
+	return err
+}
+
+func (sw *syslogWriter) Close() error {
+	sw.Lock()
+	defer sw.Unlock()
+	if !sw.closed {
+		sw.closed = true
+		close(sw.done)
+	}
+	if sw.conn == nil {
+		return nil
+	}
+	err := sw.conn.Close()
+	sw.conn = nil
+	return err
+}
+
+// format returns the message without framing
+func (sw *syslogWriter) format(r Record) []byte {
+	buf := bytes.Buffer{}
+	pri := int(sw.config.Facility)*8 + syslogSeverity(r.Level)
+	buf.WriteByte('<')
+	buf.WriteString(strconv.Itoa(pri))
+	buf.WriteByte('>')
+
+	name := recordName(r)
+	if sw.config.Format == SyslogRFC3164 {
+		buf.WriteString(r.Timestamp.Format(time.Stamp))
+		buf.WriteByte(' ')
+		buf.WriteString(syslogHeaderValue(sw.config.Hostname, 255))
+		buf.WriteByte(' ')
+		buf.WriteString(syslogHeaderValue(sw.config.AppName, 32))
+		buf.WriteString("[" + sw.pid + "]: ")
+		if name != "" {
+			buf.WriteString(name)
+			buf.WriteString(": ")
+		}
+		buf.WriteString(r.Message)
+		for _, f := range GetErrorInfo(r.Error).fields() {
+			buf.WriteByte(' ')
+			appendLogfmtKey(&buf, f.name)
+			buf.WriteByte('=')
+			appendLogfmtValue(&buf, f.value)
+		}
+		for _, f := range r.Header.fields() {
+			buf.WriteByte(' ')
+			buf.WriteString(f.name)
+			buf.WriteByte('=')
+			appendLogfmtValue(&buf, f.value)
+		}
+		for _, f := range recordStacks(r) {
+			buf.WriteByte(' ')
+			buf.WriteString(f.name)
+			buf.WriteByte('=')
+			appendLogfmtValue(&buf, f.value)
+		}
+		if sw.config.Resource {
+			for _, f := range r.Resource.fields() {
+				buf.WriteString(" resource.")
+				buf.WriteString(f.name)
+				buf.WriteByte('=')
+				appendLogfmtValue(&buf, f.value)
+			}
+		}
+		for _, n := range dataNames(r, false) {
+			buf.WriteByte(' ')
+			appendLogfmtKey(&buf, n)
+			buf.WriteByte('=')
+			appendLogfmtValue(&buf, dataValueString(r.Data[n]))
+		}
+		for _, f := range recordFields(r) {
+			buf.WriteByte(' ')
+			appendLogfmtKey(&buf, f.name)
+			buf.WriteByte('=')
+			appendLogfmtValue(&buf, f.text(time.RFC3339Nano))
+		}
+		return buf.Bytes()
+	}
+
+	buf.WriteString("1 ")
+	if r.Timestamp.IsZero() {
+		buf.WriteByte('-')
+	} else {
+		buf.WriteString(r.Timestamp.Format("2006-01-02T15:04:05.000000Z07:00"))
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(syslogHeaderValue(sw.config.Hostname, 255))
+	buf.WriteByte(' ')
+	buf.WriteString(syslogHeaderValue(sw.config.AppName, 48))
+	buf.WriteByte(' ')
+	buf.WriteString(sw.pid)
+	buf.WriteByte(' ')
+	buf.WriteString(syslogHeaderValue(name, 32))
+	buf.WriteByte(' ')
+	header := r.Header.fields()
+	var res []headerField
+	if sw.config.Resource {
+		res = r.Resource.fields()
+	}
+	stacks := recordStacks(r)
+	errFields := GetErrorInfo(r.Error).fields()
+	if len(r.Data) == 0 && len(r.Fields) == 0 && len(header) == 0 && len(res) == 0 && len(stacks) == 0 && len(errFields) == 0 {
+		buf.WriteByte('-')
+	}
+	appendSyslogElement(&buf, "error@32473", errFields)
+	appendSyslogElement(&buf, sw.config.HeaderID, header)
+	appendSyslogElement(&buf, "stack@32473", stacks)
+	appendSyslogElement(&buf, "resource@32473", res)
+	if len(r.Data) > 0 || len(r.Fields) > 0 {
+		buf.WriteByte('[')
+		buf.WriteString(sw.config.SDID)
+		for _, n := range dataNames(r, false) {
+			buf.WriteByte(' ')
+			buf.WriteString(syslogParamName(n))
+			buf.WriteString(`="`)
+			appendSyslogParamValue(&buf, dataValueString(r.Data[n]))
+			buf.WriteByte('"')
+		}
+		for _, f := range recordFields(r) {
+			buf.WriteByte(' ')
+			buf.WriteString(syslogParamName(f.name))
+			buf.WriteString(`="`)
+			appendSyslogParamValue(&buf, f.text(time.RFC3339Nano))
+			buf.WriteByte('"')
+		}
+		buf.WriteByte(']')
+	}
+	if r.Message != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(r.Message)
+	}
+	return buf.Bytes()
+} //syslogWriter.format()
+
+// recordStacks returns the stacks of the record with frames separated by "; "
+func recordStacks(r Record) []headerField {
+	var list []headerField
+	if len(r.Stack) > 0 {
+		list = append(list, headerField{name: "stack", value: stackString(r.Stack, "; ")})
+	}
+	if len(r.ErrorStack) > 0 {
+		list = append(list, headerField{name: "error_stack", value: stackString(r.ErrorStack, "; ")})
+	}
+	return list
+}
+
+// appendSyslogElement writes an SD-ELEMENT with the fields, or nothing when there are no fields
+func appendSyslogElement(buf *bytes.Buffer, id string, fields []headerField) {
+	if len(fields) == 0 {
+		return
+	}
+	buf.WriteByte('[')
+	buf.WriteString(id)
+	for _, f := range fields {
+		buf.WriteByte(' ')
+		buf.WriteString(syslogParamName(f.name))
+		buf.WriteString(`="`)
+		appendSyslogParamValue(buf, f.value)
+		buf.WriteByte('"')
+	}
+	buf.WriteByte(']')
+}
+
+// syslogHeaderValue returns s with only printable ASCII and at most max characters,
+// or "-" (the NILVALUE) when s is empty
+func syslogHeaderValue(s string, max int) string {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s) && len(b) < max; i++ {
+		if s[i] > ' ' && s[i] < 0x7f {
+			b = append(b, s[i])
+		} else {
+			b = append(b, '_')
+		}
+	}
+	if len(b) == 0 {
+		return "-"
+	}
+	return string(b)
+}
+
+// syslogParamName returns a valid SD-NAME: printable ASCII except '=', ' ', ']' and '"', at most 32 characters
+func syslogParamName(s string) string {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s) && len(b) < 32; i++ {
+		c := s[i]
+		if c <= ' ' || c >= 0x7f || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		b = append(b, c)
+	}
+	if len(b) == 0 {
+		return "_"
+	}
+	return string(b)
+}
+
+// appendSyslogParamValue writes the PARAM-VALUE escaping '"', '\' and ']'
+func appendSyslogParamValue(buf *bytes.Buffer, s string) {
+	if !utf8.ValidString(s) {
+		s = strconv.Quote(s)
+	}
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\\', ']':
+			buf.WriteByte('\\')
+		}
+		buf.WriteByte(s[i])
+	}
+}
+
+func dataValueString(v interface{}) string {
+	switch tv := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return tv
+	case time.Time:
+		return tv.Format(time.RFC3339Nano)
+	case error:
+		return tv.Error()
+	default:
+		return fmt.Sprintf("%+v", v)
+	}
+}
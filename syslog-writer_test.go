@@ -0,0 +1,193 @@
+package logger_test
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-msvc/logger"
+)
+
+func TestSyslogWriterUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	sw, err := logger.NewSyslogWriter(logger.SyslogConfig{
+		Network:  "udp",
+		Address:  pc.LocalAddr().String(),
+		Facility: logger.SyslogLocal0,
+		AppName:  "app",
+		Hostname: "host1",
+	})
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	defer sw.Close()
+
+	ts := time.Date(2021, 1, 2, 15, 4, 5, 123456789, time.UTC)
+	sw.Write(logger.Record{
+		Timestamp: ts,
+		Logger:    logger.Named("a/b"),
+		Level:     logger.LevelWarn,
+		Message:   "hello world",
+		Data:      map[string]interface{}{"id": 1, "q": `x"]\y`},
+		Keys:      []string{"q", "id"},
+	})
+	sw.Write(logger.Record{Timestamp: ts, Level: logger.LevelPanic, Message: "no data"})
+	sw.Write(logger.Record{Timestamp: ts, Level: logger.LevelInfo, Message: "header", Header: logger.Header{TraceID: "abc", Tenant: "t1"}})
+
+	pid := strconv.Itoa(os.Getpid())
+	for _, expected := range []string{
+		`<132>1 2021-01-02T15:04:05.123456Z host1 app ` + pid + ` a/b [data@32473 q="x\"\]\\y" id="1"] hello world`,
+		`<129>1 2021-01-02T15:04:05.123456Z host1 app ` + pid + ` - - no data`,
+		`<134>1 2021-01-02T15:04:05.123456Z host1 app ` + pid + ` - [header@32473 trace_id="abc" tenant="t1"] header`,
+	} {
+		buf := make([]byte, 2048)
+		pc.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if string(buf[:n]) != expected {
+			t.Fatalf("got\n%s\nexpected\n%s", buf[:n], expected)
+		}
+	}
+}
+
+func TestSyslogWriterTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	messages := make(chan string, 10)
+	conns := make(chan net.Conn, 10)
+	serve := func(ln net.Listener) {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conns <- conn
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					//octet-counting: "<len> <msg>"
+					s, err := r.ReadString(' ')
+					if err != nil {
+						return
+					}
+					n, err := strconv.Atoi(strings.TrimSpace(s))
+					if err != nil {
+						messages <- "bad frame: " + s
+						return
+					}
+					msg := make([]byte, n)
+					if _, err := io.ReadFull(r, msg); err != nil {
+						return
+					}
+					messages <- string(msg)
+				}
+			}(conn)
+		}
+	}
+	go serve(ln)
+
+	sw, err := logger.NewSyslogWriter(logger.SyslogConfig{
+		Network:  "tcp",
+		Address:  addr,
+		Format:   logger.SyslogRFC3164,
+		AppName:  "app",
+		Hostname: "host1",
+	})
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	defer sw.Close()
+
+	ts := time.Date(2021, 1, 2, 15, 4, 5, 0, time.UTC)
+	sw.Write(logger.Record{
+		Timestamp: ts,
+		Logger:    logger.Named("a/b"),
+		Level:     logger.LevelInfo,
+		Message:   "hello world",
+		Data:      map[string]interface{}{"name": "a b"},
+	})
+	expected := `<14>Jan  2 15:04:05 host1 app[` + strconv.Itoa(os.Getpid()) + `]: a/b: hello world name="a b"`
+	select {
+	case msg := <-messages:
+		if msg != expected {
+			t.Fatalf("got\n%s\nexpected\n%s", msg, expected)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("not received")
+	}
+
+	//restart the server and drop its connections, the writer must connect again
+	ln.Close()
+	(<-conns).Close()
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("cannot listen again on %s: %v", addr, err)
+	}
+	defer ln.Close()
+	go serve(ln)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		sw.Write(logger.Record{Timestamp: ts, Level: logger.LevelError, Message: "again"})
+		select {
+		case msg := <-messages:
+			if !strings.HasSuffix(msg, "]: again") || !strings.HasPrefix(msg, "<11>") {
+				t.Fatalf("after reconnect: %s", msg)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("did not reconnect")
+		}
+	}
+}
+
+func TestSyslogWriterUnix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "log")
+	pc, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Skipf("unix sockets not supported: %v", err)
+	}
+	defer pc.Close()
+
+	sw, err := logger.NewSyslogWriter(logger.SyslogConfig{Network: "unixgram", Address: path})
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	defer sw.Close()
+	sw.Write(logger.Record{Timestamp: time.Now(), Level: logger.LevelDebug, Message: "debug"})
+
+	buf := make([]byte, 2048)
+	pc.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !regexp.MustCompile(`^<15>1 \S+ \S+ \S+ \d+ - - debug$`).Match(buf[:n]) {
+		t.Fatalf("got %s", buf[:n])
+	}
+}
@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// DefaultTextLayout is the layout used by the default writer
+const DefaultTextLayout = `{{.Timestamp.Format "2006-01-02 15:04:05.000"}} {{printf "%5.5s" .Level}} {{printf "%25.5s" .Caller}}: {{.Message}}{{with .ErrorInfo}} {{.}}{{end}}{{with .Header.String}} {{.}}{{end}} {{printf "%+v" .Data}}{{range .Fields}} {{.}}{{end}}` +
+	`{{range .Stack}}{{"\n\t"}}{{frame .}}{{end}}{{with .ErrorStack}}{{"\n\terror stack:"}}{{range .}}{{"\n\t"}}{{frame .}}{{end}}{{end}}`
+
+// NewTextWriter makes a writer that writes records to w using a text/template layout
+// see NewTextEncoder() for what the layout can reference
+func NewTextWriter(w io.Writer, layout string) (IWriter, error) {
+	e, err := NewTextEncoder(layout)
+	if err != nil {
+		return nil, err
+	}
+	return NewStreamWriter(w, e), nil
+}
+
+// NewTextEncoder makes an encoder from a text/template layout
+// The layout is executed with the Record and can reference:
+//
+//	{{.Timestamp.Format "15:04:05.000"}}   time with any layout
+//	{{.Level}}                             level, e.g. {{printf "%-5s" .Level}}
+//	{{.Name}}                              full logger name
+//	{{join .Names "."}}                    logger names path
+//	{{printf "%-30.5S" .Caller}}           caller with any of its verbs (%s, %S, %f, %F), width and precision
+//	{{.Message}}                           message
+//	{{.Field "email"}}                     one data field or Field (empty when not defined)
+//	{{.ErrorInfo}}                         error set with WithError() as key=value, or {{.ErrorInfo.Type}} etc., see GetErrorInfo()
+//	{{.Header.TraceID}}                    one header field, or {{.Header}} for all that are set as key=value
+//	{{.Resource.Service}}                  one field of the process resource, see SetResource()
+//	{{range .Stack}}{{frame .}}{{end}}     stack frames when captured, also .ErrorStack
+//	{{.Data}}                              all data fields, except those replaced by a Field
+//	{{range .Fields}} {{.}}{{end}}         all fields as name=value, or {{.Name}} and {{.Value}} of each, the last of each name
+//
+// also available are funcs join, upper, lower and frame
+// a newline is added to each line when not written by the layout
+func NewTextEncoder(layout string) (IEncoder, error) {
+	t, err := template.New("log").Funcs(textFuncs).Parse(layout)
+	if err != nil {
+		return nil, err
+	}
+	return NewTemplateEncoder(t), nil
+}
+
+// NewTemplateEncoder makes an encoder from a template the caller already parsed
+// it is executed like the layout in NewTextEncoder(), but funcs must be added by the caller
+func NewTemplateEncoder(t *template.Template) IEncoder {
+	return textEncoder{t: t}
+}
+
+var textFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"frame": frameString,
+}
+
+type textEncoder struct {
+	t *template.Template
+}
+
+func (e textEncoder) Encode(buf *bytes.Buffer, r Record) {
+	if err := e.t.Execute(buf, textRecord{r}); err != nil {
+		buf.WriteString(" !ERROR: " + err.Error())
+	}
+	if buf.Len() == 0 || buf.Bytes()[buf.Len()-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+}
+
+// textRecord is the data used to execute a text layout
+type textRecord struct {
+	Record
+}
+
+func (r textRecord) Name() string { return recordName(r.Record) }
+
+func (r textRecord) ErrorInfo() *ErrorInfo { return GetErrorInfo(r.Error) }
+
+// Resource is never nil, so that a layout can use {{.Resource.Service}} with records made without a logger
+func (r textRecord) Resource() *Resource {
+	if r.Record.Resource == nil {
+		return &Resource{}
+	}
+	return r.Record.Resource
+}
+
+func (r textRecord) Names() []string {
+	if r.Logger == nil {
+		return nil
+	}
+	return r.Logger.Names()
+}
+
+// Data returns the data without the names that are replaced by a field
+func (r textRecord) Data() map[string]interface{} { return recordData(r.Record) }
+
+// Fields returns the fields without those replaced by a later field with the same name
+func (r textRecord) Fields() []Field { return recordFields(r.Record) }
+
+// Field returns the last field with the name, else the data value
+func (r textRecord) Field(name string) interface{} {
+	for i := len(r.Record.Fields) - 1; i >= 0; i-- {
+		if r.Record.Fields[i].name == name {
+			return r.Record.Fields[i].Value()
+		}
+	}
+	if v, ok := r.Record.Data[name]; ok {
+		return v
+	}
+	return ""
+}
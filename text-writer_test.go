@@ -0,0 +1,45 @@
+package logger_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/go-msvc/logger"
+)
+
+func TestTextWriter(t *testing.T) {
+	tests := []struct {
+		layout   string
+		expected string
+	}{
+		{`{{.Timestamp.Format "15:04:05"}} {{printf "%-5s" .Level}} {{.Name}} {{.Message}}`, "15:04:05 INFO  github.com/go-msvc/logger_test hello\n"},
+		{`{{upper (join .Names "|")}} {{printf "%f" .Caller}} email={{.Field "email"}} x={{.Field "x"}}` + "\n", "GITHUB.COM|GO-MSVC|LOGGER_TEST TestTextWriter(31) email=a@b.c x=\n"},
+		{`{{printf "%10.3s" .Caller}}`, "st.go( 31)\n"},
+	}
+	for index, test := range tests {
+		buf := bytes.NewBuffer(nil)
+		w, err := logger.NewTextWriter(buf, test.layout)
+		if err != nil {
+			t.Fatalf("test[%d]: %+v", index, err)
+		}
+		l := logger.New().WithLevel(logger.LevelDebug)
+		l.SetWriter(writerFunc(func(r logger.Record) {
+			r.Timestamp = time.Date(2021, 1, 2, 15, 4, 5, 0, time.UTC)
+			w.Write(r)
+		}))
+		l.With("email", "a@b.c").Info("hello") //line 31
+		l.SetWriter(nil)
+		if buf.String() != test.expected {
+			t.Fatalf("test[%d]: %q != %q", index, buf.String(), test.expected)
+		}
+	}
+
+	if _, err := logger.NewTextWriter(nil, "{{.Message"); err == nil {
+		t.Fatalf("expected layout error")
+	}
+}
+
+type writerFunc func(logger.Record)
+
+func (f writerFunc) Write(r logger.Record) { f(r) }
@@ -9,21 +9,22 @@ func Named(name string) Logger {
 	return top.New(name)
 }
 
+// top is created when declared rather than in init()
+// so that it exists before any init() in this package runs
 var (
-	top *named
+	top = newTop()
 )
 
-func init() {
-	top = &named{
-		name:   "",
-		parent: nil,
-		subs:   map[string]*named{},
-		level:  LevelError,
-		writer: defaultWriter{},
-	}
+func newTop() *named {
+	t := newNamed("", nil, nil, LevelError, stackOff, defaultWriter{})
+	t.ownLevel = LevelError
+	t.ownStackLevel = stackOff
+	return t
 }
 
 // SetGlobalWriter sets the writer on top and all existing and default for all new loggers
+// except loggers that called DetachWriter()
+// the writers it replaces are flushed and closed when no logger uses them any more
 func SetGlobalWriter(newWriter IWriter) {
 	top.setWriter(newWriter)
 }
@@ -31,6 +32,8 @@ func SetGlobalWriter(newWriter IWriter) {
 // careful with this - will also set for all named loggers used in libraries
 // rather create your own logger and set its level, or set level on Named(...).SetLevel(...)
 // still: setting level like this will only affect loggers that did not set their own levels
+// and names that did not set their own level with Named(...).SetLevel(), e.g. after
+// Named("github.com/go-msvc").SetLevel(LevelDebug) all go-msvc packages stay on debug
 // libraries should just create logger that defaults to level error, and then will be changed
 // by this or Named(...).SetLevel()
 // If a library did New().WithLevel(), it will not be affected by any of this...
@@ -40,6 +43,19 @@ func SetGlobalLevel(newLevel Level) {
 	top.setLevel(newLevel)
 }
 
+// SetGlobalStackLevel makes records at or above the level capture the stack of the goroutine
+// in Record.Stack, for all names that did not set their own with SetStackLevel()
+// by default no stacks are captured, e.g. SetGlobalStackLevel(LevelError) for errors, fatal and panic
+func SetGlobalStackLevel(newLevel Level) {
+	top.setStackLevel(newLevel)
+}
+
+// SetGlobalStackOff stops capturing stacks, which is the default,
+// for all names that did not set their own with SetStackLevel() or SetStackOff()
+func SetGlobalStackOff() {
+	top.setStackLevel(stackOff)
+}
+
 func All() map[string]INamed {
 	return top.All()
 }
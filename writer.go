@@ -1,22 +1,46 @@
 package logger
 
 import (
-	"fmt"
+	"bytes"
+	"context"
 	"os"
+	"text/template"
 )
 
 type IWriter interface {
 	Write(Record)
 }
 
+// IFlusher is implemented by writers that buffer records
+// Flush returns when all records written before the call were delivered or ctx is done
+type IFlusher interface {
+	Flush(ctx context.Context) error
+}
+
+// ICloser is implemented by writers that hold resources such as files or connections
+// a writer is closed by Shutdown(), and when SetWriter() replaces it and no logger uses it
+// any more, also not inside another writer, see IWrapper
+type ICloser interface {
+	Close() error
+}
+
+// IWrapper is implemented by writers that write to other writers, e.g. NewMultiWriter()
+// so that Flush(), Shutdown() and SetWriter() find the writers inside and flush or close each one once
+// they flush and close a wrapper before the writers it wraps, so a wrapper made outside this
+// package that implements IFlusher or ICloser must not pass those calls on to its writers
+type IWrapper interface {
+	Writers() []IWriter
+}
+
+// defaultWriter writes records to stderr with DefaultTextLayout
 type defaultWriter struct{}
 
+var defaultEncoder = NewTemplateEncoder(template.Must(template.New("log").Funcs(textFuncs).Parse(DefaultTextLayout)))
+
 func (w defaultWriter) Write(r Record) {
-	fmt.Fprintf(os.Stderr, "%s %5.5s %25.5s: %s %+v\n",
-		r.Timestamp.Format("2006-01-02 15:04:05.000"),
-		r.Level.String(),
-		r.Caller,
-		r.Message,
-		r.Data,
-	)
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defaultEncoder.Encode(buf, r)
+	os.Stderr.Write(buf.Bytes())
+	bufferPool.Put(buf)
 }